@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	fakeTenant   = "fake_tenant"
+	fakeClientId = "fake_client_id"
+)
+
+// setup starts a stand-in for the Microsoft identity platform and returns a
+// Config pointing at it.
+func setup() (config *Config, mux *http.ServeMux, teardown func()) {
+	mux = http.NewServeMux()
+	server := httptest.NewServer(mux)
+	config = &Config{
+		ClientId: fakeClientId,
+		Scopes:   []string{"Files.ReadWrite", OfflineAccessScope},
+		Endpoint: MicrosoftEndpoint(server.URL, fakeTenant),
+	}
+	return config, mux, server.Close
+}
+
+func testForm(t *testing.T, r *http.Request, key, want string) {
+	t.Helper()
+	if got := r.PostFormValue(key); got != want {
+		t.Errorf("Form value %q: %q, want %q", key, got, want)
+	}
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write([]byte(body))
+}
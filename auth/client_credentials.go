@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	clientAssertionType     = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	clientAssertionLifetime = 10 * time.Minute
+)
+
+// Certificate authenticates an application with a certificate registered in
+// Azure AD instead of a client secret.
+type Certificate struct {
+	Key         *rsa.PrivateKey
+	Certificate *x509.Certificate
+}
+
+// assertion returns a JWT signed with the certificate key, as described in
+// https://learn.microsoft.com/en-us/entra/identity-platform/certificate-credentials.
+func (c *Certificate) assertion(clientId, audience string) (string, error) {
+	thumbprint := sha1.Sum(c.Certificate.Raw)
+	thumbprintS256 := sha256.Sum256(c.Certificate.Raw)
+	jti, err := randomString(16)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	header, err := json.Marshal(map[string]string{
+		"alg":      "RS256",
+		"typ":      "JWT",
+		"x5t":      base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+		"x5t#S256": base64.RawURLEncoding.EncodeToString(thumbprintS256[:]),
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": audience,
+		"iss": clientId,
+		"sub": clientId,
+		"jti": jti,
+		"nbf": now.Unix(),
+		"iat": now.Unix(),
+		"exp": now.Add(clientAssertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.Key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// ClientCredentialsToken requests an app-only token with the client secret,
+// or with certificate when it is not nil. Scopes default to GraphDefaultScope.
+func (c *Config) ClientCredentialsToken(ctx context.Context, certificate *Certificate) (*Token, error) {
+	values := url.Values{
+		"grant_type": {"client_credentials"},
+	}
+	if len(c.Scopes) == 0 {
+		values.Set("scope", GraphDefaultScope)
+	}
+	if certificate != nil {
+		assertion, err := certificate.assertion(c.ClientId, c.Endpoint.TokenURL)
+		if err != nil {
+			return nil, err
+		}
+		values.Set("client_assertion_type", clientAssertionType)
+		values.Set("client_assertion", assertion)
+	}
+	return c.retrieveToken(ctx, values)
+}
+
+// ClientCredentialsTokenSource acquires app-only tokens and acquires a new one
+// when the current token expires. It is safe for concurrent use.
+type ClientCredentialsTokenSource struct {
+	config      *Config
+	certificate *Certificate
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewClientCredentialsTokenSource returns a token source for an application
+// authenticating as itself. certificate may be nil to use the client secret.
+func NewClientCredentialsTokenSource(config *Config, certificate *Certificate) *ClientCredentialsTokenSource {
+	return &ClientCredentialsTokenSource{
+		config:      config,
+		certificate: certificate,
+	}
+}
+
+func (s *ClientCredentialsTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	token, err := s.config.ClientCredentialsToken(ctx, s.certificate)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// Invalidate forces the next call to Token to acquire a new token if token is still the current token.
+func (s *ClientCredentialsTokenSource) Invalidate(token *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != nil && token != nil && s.token.AccessToken == token.AccessToken {
+		s.token = nil
+	}
+}
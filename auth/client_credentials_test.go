@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFakeCertificate(t *testing.T) *Certificate {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey failed: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fake_app"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate failed: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &Certificate{Key: key, Certificate: cert}
+}
+
+func TestConfig_ClientCredentialsToken_Secret(t *testing.T) {
+	config, mux, teardown := setup()
+	defer teardown()
+	config.Scopes = nil
+	config.ClientSecret = "fake_client_secret"
+
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		testForm(t, r, "grant_type", "client_credentials")
+		testForm(t, r, "client_id", fakeClientId)
+		testForm(t, r, "client_secret", "fake_client_secret")
+		testForm(t, r, "scope", GraphDefaultScope)
+		writeJson(w, http.StatusOK, `{"access_token": "fake_app_token", "expires_in": 3599}`)
+	})
+
+	token, err := config.ClientCredentialsToken(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Config.ClientCredentialsToken returned error: %v", err)
+	}
+	if token.AccessToken != "fake_app_token" {
+		t.Errorf("Config.ClientCredentialsToken returned %+v", token)
+	}
+}
+
+func TestConfig_ClientCredentialsToken_Certificate(t *testing.T) {
+	config, mux, teardown := setup()
+	defer teardown()
+	config.Scopes = nil
+	certificate := newFakeCertificate(t)
+
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		testForm(t, r, "client_assertion_type", clientAssertionType)
+		testForm(t, r, "client_secret", "")
+		parts := strings.Split(r.PostFormValue("client_assertion"), ".")
+		if len(parts) != 3 {
+			t.Errorf("client_assertion has %d parts, want 3", len(parts))
+			return
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		if err := rsa.VerifyPKCS1v15(&certificate.Key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			t.Errorf("client_assertion signature is invalid: %v", err)
+		}
+		claimsJson, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims map[string]interface{}
+		json.Unmarshal(claimsJson, &claims)
+		if claims["aud"] != config.Endpoint.TokenURL || claims["iss"] != fakeClientId || claims["sub"] != fakeClientId {
+			t.Errorf("client_assertion claims: %v", claims)
+		}
+		writeJson(w, http.StatusOK, `{"access_token": "fake_app_token", "expires_in": 3599}`)
+	})
+
+	token, err := NewClientCredentialsTokenSource(config, certificate).Token(context.Background())
+	if err != nil {
+		t.Fatalf("ClientCredentialsTokenSource.Token returned error: %v", err)
+	}
+	if token.AccessToken != "fake_app_token" {
+		t.Errorf("ClientCredentialsTokenSource.Token returned %+v", token)
+	}
+}
+
+func TestClientCredentialsTokenSource_Token_Cached(t *testing.T) {
+	config, mux, teardown := setup()
+	defer teardown()
+	config.ClientSecret = "fake_client_secret"
+
+	calls := 0
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		writeJson(w, http.StatusOK, `{"access_token": "fake_app_token", "expires_in": 3599}`)
+	})
+
+	source := NewClientCredentialsTokenSource(config, nil)
+	source.Token(context.Background())
+	token, _ := source.Token(context.Background())
+	if calls != 1 {
+		t.Errorf("Token endpoint received %d requests, want 1", calls)
+	}
+	source.Invalidate(token)
+	source.Token(context.Background())
+	if calls != 2 {
+		t.Errorf("Token endpoint received %d requests after Invalidate, want 2", calls)
+	}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultAuthority is the Microsoft identity platform host.
+	DefaultAuthority = "https://login.microsoftonline.com"
+	// DefaultTenant lets both work or school and personal Microsoft accounts sign in.
+	DefaultTenant = "common"
+	// GraphDefaultScope requests every application permission granted to the app.
+	GraphDefaultScope = "https://graph.microsoft.com/.default"
+	// OfflineAccessScope requests a refresh token.
+	OfflineAccessScope = "offline_access"
+)
+
+// Endpoint contains the Microsoft identity platform URLs used by the flows.
+type Endpoint struct {
+	AuthURL       string
+	TokenURL      string
+	DeviceAuthURL string
+}
+
+// MicrosoftEndpoint returns the OAuth2 v2.0 endpoints of tenant under authority.
+// Pass DefaultAuthority unless the tokens come from a national cloud or a local
+// stand-in server.
+func MicrosoftEndpoint(authority, tenant string) Endpoint {
+	base := strings.TrimSuffix(authority, "/") + "/" + tenant + "/oauth2/v2.0"
+	return Endpoint{
+		AuthURL:       base + "/authorize",
+		TokenURL:      base + "/token",
+		DeviceAuthURL: base + "/devicecode",
+	}
+}
+
+// Config describes an application registered in Azure AD.
+type Config struct {
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+	Endpoint     Endpoint
+	// HttpClient is used to call the identity endpoints. http.DefaultClient is used when nil.
+	HttpClient *http.Client
+}
+
+func (c *Config) httpClient() *http.Client {
+	if c.HttpClient != nil {
+		return c.HttpClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Config) scope() string {
+	return strings.Join(c.Scopes, " ")
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/bearcatat/onedrive-api/http"
+)
+
+const (
+	deviceCodeGrantType      = "urn:ietf:params:oauth:grant-type:device_code"
+	defaultDeviceInterval    = 5 * time.Second
+	slowDownIntervalIncrease = 5 * time.Second
+
+	errorAuthorizationPending  = "authorization_pending"
+	errorSlowDown              = "slow_down"
+	errorExpiredToken          = "expired_token"
+	errorAuthorizationDeclined = "authorization_declined"
+)
+
+var (
+	ErrDeviceCodeExpired = errors.New("device code expired")
+	ErrLoginDeclined     = errors.New("login declined by user")
+)
+
+// sleep waits between polls of the token endpoint. Tests replace it.
+var sleep = http.Sleep
+
+// DeviceCode is returned when a device code login starts. Show Message, or
+// UserCode and VerificationURI, to the user.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Interval        int64  `json:"interval"`
+	Message         string `json:"message"`
+
+	expiry time.Time
+}
+
+func (d *DeviceCode) interval() time.Duration {
+	if d.Interval <= 0 {
+		return defaultDeviceInterval
+	}
+	return time.Duration(d.Interval) * time.Second
+}
+
+// StartDeviceCode starts a device code login.
+func (c *Config) StartDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	values := url.Values{
+		"client_id": {c.ClientId},
+		"scope":     {c.scope()},
+	}
+	var deviceCode *DeviceCode
+	if err := c.postForm(ctx, c.Endpoint.DeviceAuthURL, values, &deviceCode); err != nil {
+		return nil, err
+	}
+	if deviceCode == nil || deviceCode.DeviceCode == "" {
+		return nil, ErrInvalidResponse
+	}
+	if deviceCode.ExpiresIn > 0 {
+		deviceCode.expiry = time.Now().Add(time.Duration(deviceCode.ExpiresIn) * time.Second)
+	}
+	return deviceCode, nil
+}
+
+// PollDeviceToken polls the token endpoint at the interval advertised by the
+// server until the user completes the login, the code expires or ctx is done.
+func (c *Config) PollDeviceToken(ctx context.Context, deviceCode *DeviceCode) (*Token, error) {
+	interval := deviceCode.interval()
+	for {
+		if !deviceCode.expiry.IsZero() && time.Now().After(deviceCode.expiry) {
+			return nil, ErrDeviceCodeExpired
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+		token, err := c.retrieveToken(ctx, url.Values{
+			"grant_type":  {deviceCodeGrantType},
+			"device_code": {deviceCode.DeviceCode},
+		})
+		if err == nil {
+			return token, nil
+		}
+		var tokenError *TokenError
+		if !errors.As(err, &tokenError) {
+			return nil, err
+		}
+		switch tokenError.ErrorCode {
+		case errorAuthorizationPending:
+		case errorSlowDown:
+			interval += slowDownIntervalIncrease
+		case errorExpiredToken:
+			return nil, ErrDeviceCodeExpired
+		case errorAuthorizationDeclined:
+			return nil, ErrLoginDeclined
+		default:
+			return nil, err
+		}
+	}
+}
+
+// DeviceCodeLogin runs a device code login for machines without a browser.
+// prompt is called once with the code the user has to enter at the
+// verification URI.
+func (c *Config) DeviceCodeLogin(ctx context.Context, prompt func(*DeviceCode) error) (*Token, error) {
+	deviceCode, err := c.StartDeviceCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := prompt(deviceCode); err != nil {
+		return nil, err
+	}
+	return c.PollDeviceToken(ctx, deviceCode)
+}
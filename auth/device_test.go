@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func setup_device(t *testing.T) (config *Config, mux *http.ServeMux, sleeps *[]time.Duration, teardown func()) {
+	config, mux, teardownServer := setup()
+	sleeps = &[]time.Duration{}
+	originalSleep := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		return ctx.Err()
+	}
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/devicecode", func(w http.ResponseWriter, r *http.Request) {
+		testForm(t, r, "client_id", fakeClientId)
+		testForm(t, r, "scope", "Files.ReadWrite offline_access")
+		writeJson(w, http.StatusOK, `{
+			"device_code": "fake_device_code",
+			"user_code": "FAKECODE",
+			"verification_uri": "https://microsoft.com/devicelogin",
+			"expires_in": 900,
+			"interval": 2,
+			"message": "To sign in, enter the code FAKECODE"
+		}`)
+	})
+	return config, mux, sleeps, func() {
+		sleep = originalSleep
+		teardownServer()
+	}
+}
+
+func TestConfig_DeviceCodeLogin(t *testing.T) {
+	config, mux, sleeps, teardown := setup_device(t)
+	defer teardown()
+
+	responses := []string{
+		`{"error": "authorization_pending"}`,
+		`{"error": "slow_down"}`,
+		`{"error": "authorization_pending"}`,
+	}
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		testForm(t, r, "grant_type", deviceCodeGrantType)
+		testForm(t, r, "device_code", "fake_device_code")
+		if len(responses) > 0 {
+			writeJson(w, http.StatusBadRequest, responses[0])
+			responses = responses[1:]
+			return
+		}
+		writeJson(w, http.StatusOK, `{"access_token": "fake_access_token", "refresh_token": "fake_refresh_token", "expires_in": 3600}`)
+	})
+
+	var prompted *DeviceCode
+	token, err := config.DeviceCodeLogin(context.Background(), func(code *DeviceCode) error {
+		prompted = code
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Config.DeviceCodeLogin returned error: %v", err)
+	}
+	if token.AccessToken != "fake_access_token" || token.RefreshToken != "fake_refresh_token" {
+		t.Errorf("Config.DeviceCodeLogin returned %+v", token)
+	}
+	if prompted == nil || prompted.UserCode != "FAKECODE" || prompted.VerificationURI != "https://microsoft.com/devicelogin" {
+		t.Errorf("Config.DeviceCodeLogin prompted with %+v", prompted)
+	}
+	expectedSleeps := []time.Duration{2 * time.Second, 2 * time.Second, 7 * time.Second, 7 * time.Second}
+	if !reflect.DeepEqual(*sleeps, expectedSleeps) {
+		t.Errorf("Config.DeviceCodeLogin waited %v, want %v", *sleeps, expectedSleeps)
+	}
+}
+
+func TestConfig_DeviceCodeLogin_Expired(t *testing.T) {
+	config, mux, _, teardown := setup_device(t)
+	defer teardown()
+
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusBadRequest, `{"error": "expired_token"}`)
+	})
+
+	_, err := config.DeviceCodeLogin(context.Background(), func(code *DeviceCode) error { return nil })
+	if err != ErrDeviceCodeExpired {
+		t.Errorf("Config.DeviceCodeLogin returned %v, want %v", err, ErrDeviceCodeExpired)
+	}
+}
+
+func TestConfig_DeviceCodeLogin_Canceled(t *testing.T) {
+	config, mux, _, teardown := setup_device(t)
+	defer teardown()
+
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusBadRequest, `{"error": "authorization_pending"}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := config.DeviceCodeLogin(ctx, func(code *DeviceCode) error {
+		cancel()
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("Config.DeviceCodeLogin returned %v, want %v", err, context.Canceled)
+	}
+}
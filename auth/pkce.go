@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+const (
+	pkceMethodS256 = "S256"
+)
+
+var (
+	ErrStateMismatch = errors.New("state mismatch")
+	ErrNoCode        = errors.New("no authorization code")
+)
+
+// PKCE holds a proof key for code exchange.
+type PKCE struct {
+	Verifier  string
+	Challenge string
+	Method    string
+}
+
+// NewPKCE generates a random verifier and its S256 challenge.
+func NewPKCE() (*PKCE, error) {
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return &PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+		Method:    pkceMethodS256,
+	}, nil
+}
+
+func randomString(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL returns the URL of the login page the user has to open.
+func (c *Config) AuthCodeURL(state, redirectURL string, pkce *PKCE) string {
+	values := url.Values{
+		"client_id":             {c.ClientId},
+		"response_type":         {"code"},
+		"redirect_uri":          {redirectURL},
+		"response_mode":         {"query"},
+		"scope":                 {c.scope()},
+		"state":                 {state},
+		"code_challenge":        {pkce.Challenge},
+		"code_challenge_method": {pkce.Method},
+	}
+	return c.Endpoint.AuthURL + "?" + values.Encode()
+}
+
+// ExchangeCode exchanges an authorization code for a token.
+func (c *Config) ExchangeCode(ctx context.Context, code, redirectURL string, pkce *PKCE) (*Token, error) {
+	return c.retrieveToken(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+		"code_verifier": {pkce.Verifier},
+	})
+}
+
+// LoopbackLogin runs an authorization code login with PKCE for desktop tools.
+// It listens on a random 127.0.0.1 port for the redirect, calls openBrowser
+// with the login URL and exchanges the returned code for a token. The
+// redirect URI sent is http://127.0.0.1:{port}/: add http://127.0.0.1 as a
+// redirect URI of the application, in its manifest if the portal rejects it.
+// The port of loopback redirect URIs is not compared.
+func (c *Config) LoopbackLogin(ctx context.Context, openBrowser func(authURL string) error) (*Token, error) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	redirectURL := fmt.Sprintf("http://%s/", listener.Addr().String())
+
+	results := make(chan loopbackResult, 1)
+	server := &http.Server{
+		Handler: loopbackHandler(state, results),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := openBrowser(c.AuthCodeURL(state, redirectURL, pkce)); err != nil {
+		return nil, err
+	}
+	var result loopbackResult
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result = <-results:
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+	return c.ExchangeCode(ctx, result.code, redirectURL, pkce)
+}
+
+type loopbackResult struct {
+	code string
+	err  error
+}
+
+// loopbackHandler captures the first redirect back from the login page.
+func loopbackHandler(state string, results chan<- loopbackResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		var result loopbackResult
+		switch {
+		case query.Get("state") != state:
+			result.err = ErrStateMismatch
+		case query.Get("error") != "":
+			result.err = &TokenError{
+				ErrorCode:   query.Get("error"),
+				Description: query.Get("error_description"),
+			}
+		case query.Get("code") == "":
+			result.err = ErrNoCode
+		default:
+			result.code = query.Get("code")
+		}
+		if result.err != nil {
+			http.Error(w, "Login failed: "+result.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Login succeeded, you can close this window.")
+		}
+		select {
+		case results <- result:
+		default:
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+// setup_authorize serves a login page that immediately redirects back with
+// code, as if the user had signed in.
+func setup_authorize(t *testing.T, mux *http.ServeMux, redirect func(query url.Values) url.Values) (challenge *string) {
+	challenge = new(string)
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if got := query.Get("client_id"); got != fakeClientId {
+			t.Errorf("client_id: %q, want %q", got, fakeClientId)
+		}
+		if got := query.Get("code_challenge_method"); got != "S256" {
+			t.Errorf("code_challenge_method: %q, want S256", got)
+		}
+		*challenge = query.Get("code_challenge")
+		http.Redirect(w, r, query.Get("redirect_uri")+"?"+redirect(query).Encode(), http.StatusFound)
+	})
+	return challenge
+}
+
+func openInBrowser(t *testing.T) func(authURL string) error {
+	return func(authURL string) error {
+		go func() {
+			resp, err := http.Get(authURL)
+			if err != nil {
+				t.Errorf("Browser failed to open %s: %v", authURL, err)
+				return
+			}
+			resp.Body.Close()
+		}()
+		return nil
+	}
+}
+
+func TestConfig_LoopbackLogin(t *testing.T) {
+	config, mux, teardown := setup()
+	defer teardown()
+
+	var redirectURI string
+	challenge := setup_authorize(t, mux, func(query url.Values) url.Values {
+		redirectURI = query.Get("redirect_uri")
+		if !regexp.MustCompile(`^http://127\.0\.0\.1:[0-9]+/$`).MatchString(redirectURI) {
+			t.Errorf("redirect_uri: %q, want http://127.0.0.1:{port}/", redirectURI)
+		}
+		return url.Values{"code": {"fake_code"}, "state": {query.Get("state")}}
+	})
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		testForm(t, r, "grant_type", "authorization_code")
+		testForm(t, r, "code", "fake_code")
+		testForm(t, r, "redirect_uri", redirectURI)
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != *challenge {
+			t.Errorf("code_verifier does not match code_challenge %q", *challenge)
+		}
+		writeJson(w, http.StatusOK, `{"access_token": "fake_access_token", "refresh_token": "fake_refresh_token", "expires_in": 3600}`)
+	})
+
+	token, err := config.LoopbackLogin(context.Background(), openInBrowser(t))
+	if err != nil {
+		t.Fatalf("Config.LoopbackLogin returned error: %v", err)
+	}
+	if token.AccessToken != "fake_access_token" || token.RefreshToken != "fake_refresh_token" {
+		t.Errorf("Config.LoopbackLogin returned %+v", token)
+	}
+}
+
+func TestConfig_LoopbackLogin_StateMismatch(t *testing.T) {
+	config, mux, teardown := setup()
+	defer teardown()
+
+	setup_authorize(t, mux, func(query url.Values) url.Values {
+		return url.Values{"code": {"fake_code"}, "state": {"forged_state"}}
+	})
+
+	_, err := config.LoopbackLogin(context.Background(), openInBrowser(t))
+	if err != ErrStateMismatch {
+		t.Errorf("Config.LoopbackLogin returned %v, want %v", err, ErrStateMismatch)
+	}
+}
+
+func TestConfig_LoopbackLogin_AccessDenied(t *testing.T) {
+	config, mux, teardown := setup()
+	defer teardown()
+
+	setup_authorize(t, mux, func(query url.Values) url.Values {
+		return url.Values{"error": {"access_denied"}, "state": {query.Get("state")}}
+	})
+
+	_, err := config.LoopbackLogin(context.Background(), openInBrowser(t))
+	tokenError, ok := err.(*TokenError)
+	if !ok || tokenError.ErrorCode != "access_denied" {
+		t.Errorf("Config.LoopbackLogin returned %v, want access_denied", err)
+	}
+}
+
+func TestNewPKCE(t *testing.T) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE returned error: %v", err)
+	}
+	if len(pkce.Verifier) < 43 || len(pkce.Verifier) > 128 {
+		t.Errorf("NewPKCE returned a verifier of %d characters, want 43 to 128", len(pkce.Verifier))
+	}
+	sum := sha256.Sum256([]byte(pkce.Verifier))
+	if pkce.Challenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		t.Errorf("NewPKCE returned challenge %q that does not match the verifier", pkce.Challenge)
+	}
+}
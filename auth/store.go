@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// TokenStore persists tokens between runs. Load returns a nil token when the
+// store is empty.
+type TokenStore interface {
+	Load() (*Token, error)
+	Save(token *Token) error
+}
+
+// MemoryStore keeps the token in memory.
+type MemoryStore struct {
+	mu    sync.Mutex
+	token *Token
+}
+
+func NewMemoryStore(token *Token) *MemoryStore {
+	return &MemoryStore{
+		token: token,
+	}
+}
+
+func (s *MemoryStore) Load() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil {
+		return nil, nil
+	}
+	token := *s.token
+	return &token, nil
+}
+
+func (s *MemoryStore) Save(token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *token
+	s.token = &saved
+	return nil
+}
+
+// FileStore keeps the token in a JSON file readable only by the current user.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+	}
+}
+
+func (s *FileStore) Load() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var token *Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Save writes the token to a temporary file and renames it over the store so
+// that a crash never leaves a truncated token behind.
+func (s *FileStore) Save(token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
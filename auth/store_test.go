@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	store := NewFileStore(path)
+
+	token, err := store.Load()
+	if err != nil || token != nil {
+		t.Errorf("FileStore.Load returned %v, %v, want nil, nil", token, err)
+	}
+
+	expected := &Token{
+		AccessToken:  "fake_access_token",
+		TokenType:    "Bearer",
+		RefreshToken: "fake_refresh_token",
+		Expiry:       time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	if err := store.Save(expected); err != nil {
+		t.Fatalf("FileStore.Save returned error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("FileStore.Save did not create %s: %v", path, err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("FileStore.Save created %s with mode %v, want 0600", path, perm)
+	}
+
+	token, err = NewFileStore(path).Load()
+	if err != nil {
+		t.Fatalf("FileStore.Load returned error: %v", err)
+	}
+	if *token != *expected {
+		t.Errorf("FileStore.Load returned %+v, want %+v", token, expected)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore(nil)
+	if token, _ := store.Load(); token != nil {
+		t.Errorf("MemoryStore.Load returned %+v, want nil", token)
+	}
+	store.Save(&Token{AccessToken: "fake_access_token"})
+	if token, _ := store.Load(); token.AccessToken != "fake_access_token" {
+		t.Errorf("MemoryStore.Load returned %+v, want the saved token", token)
+	}
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// expiryDelta refreshes tokens slightly before they expire so that a
+	// request does not start with a token that expires in flight.
+	expiryDelta = 30 * time.Second
+)
+
+var (
+	ErrNoToken         = errors.New("no token")
+	ErrNoRefreshToken  = errors.New("token expired and has no refresh token")
+	ErrInvalidResponse = errors.New("invalid token response")
+)
+
+// Token is an OAuth2 token issued by the Microsoft identity platform.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"`
+}
+
+// Valid reports whether the token has an access token that is not about to expire.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+func (t *Token) authorizationHeader() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// TokenSource returns tokens for authenticating requests.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// invalidator is implemented by token sources that can drop a token the
+// server rejected before it expired.
+type invalidator interface {
+	Invalidate(token *Token)
+}
+
+// TokenError is returned when the token endpoint rejects a request.
+type TokenError struct {
+	StatusCode  int
+	ErrorCode   string `json:"error"`
+	Description string `json:"error_description"`
+	ErrorCodes  []int  `json:"error_codes"`
+}
+
+func (e *TokenError) Error() string {
+	if e.Description == "" {
+		return e.ErrorCode
+	}
+	return fmt.Sprintf("%s-%s", e.ErrorCode, e.Description)
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func (r *tokenResponse) token() *Token {
+	token := &Token{
+		AccessToken:  r.AccessToken,
+		TokenType:    r.TokenType,
+		RefreshToken: r.RefreshToken,
+	}
+	if r.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(r.ExpiresIn) * time.Second)
+	}
+	return token
+}
+
+// postForm posts values to endpoint and decodes a successful JSON response into target.
+func (c *Config) postForm(ctx context.Context, endpoint string, values url.Values, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		tokenError := &TokenError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(body, tokenError) != nil || tokenError.ErrorCode == "" {
+			tokenError.ErrorCode = http.StatusText(resp.StatusCode)
+			tokenError.Description = strings.TrimSpace(string(body))
+		}
+		return tokenError
+	}
+	return json.Unmarshal(body, target)
+}
+
+// retrieveToken requests a token from the token endpoint with the given grant.
+func (c *Config) retrieveToken(ctx context.Context, values url.Values) (*Token, error) {
+	values.Set("client_id", c.ClientId)
+	if c.ClientSecret != "" && values.Get("client_assertion") == "" {
+		values.Set("client_secret", c.ClientSecret)
+	}
+	if len(c.Scopes) > 0 && values.Get("scope") == "" {
+		values.Set("scope", c.scope())
+	}
+	var resp tokenResponse
+	if err := c.postForm(ctx, c.Endpoint.TokenURL, values, &resp); err != nil {
+		return nil, err
+	}
+	if resp.AccessToken == "" {
+		return nil, ErrInvalidResponse
+	}
+	return resp.token(), nil
+}
+
+// RefreshToken exchanges the refresh token of token for a new token.
+func (c *Config) RefreshToken(ctx context.Context, token *Token) (*Token, error) {
+	if token == nil || token.RefreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+	newToken, err := c.retrieveToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if newToken.RefreshToken == "" {
+		newToken.RefreshToken = token.RefreshToken
+	}
+	return newToken, nil
+}
+
+// RefreshTokenSource returns the token kept in a TokenStore and refreshes it
+// when it expires or is rejected by the server. Refreshed tokens are saved back
+// to the store. It is safe for concurrent use.
+type RefreshTokenSource struct {
+	config *Config
+	store  TokenStore
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewTokenSource returns a token source for tokens kept in store. The store
+// must already hold a token, e.g. one obtained with one of the login flows.
+func NewTokenSource(config *Config, store TokenStore) *RefreshTokenSource {
+	return &RefreshTokenSource{
+		config: config,
+		store:  store,
+	}
+}
+
+func (s *RefreshTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil {
+		token, err := s.store.Load()
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			return nil, ErrNoToken
+		}
+		s.token = token
+	}
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	token, err := s.config.RefreshToken(ctx, s.token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(token); err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// Invalidate forces the next call to Token to refresh if token is still the current token.
+func (s *RefreshTokenSource) Invalidate(token *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != nil && token != nil && s.token.AccessToken == token.AccessToken {
+		s.token = &Token{
+			RefreshToken: s.token.RefreshToken,
+			TokenType:    s.token.TokenType,
+		}
+	}
+}
+
+// StaticTokenSource returns the same token forever.
+func StaticTokenSource(token *Token) TokenSource {
+	return staticTokenSource{token: token}
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+func (s staticTokenSource) Token(ctx context.Context) (*Token, error) {
+	return s.token, nil
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToken_JSON_NoExpiry(t *testing.T) {
+	token := &Token{AccessToken: "fake_access_token", RefreshToken: "fake_refresh_token"}
+	data, err := json.Marshal(token)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+	if strings.Contains(string(data), "expiry") {
+		t.Errorf("json.Marshal returned %s, want no expiry", data)
+	}
+	var got *Token
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal returned error: %v", err)
+	}
+	if *got != *token {
+		t.Errorf("json.Unmarshal returned %+v, want %+v", got, token)
+	}
+	if !got.Valid() {
+		t.Errorf("Token.Valid returned false for a token without expiry")
+	}
+}
+
+func TestRefreshTokenSource_Token_Refresh(t *testing.T) {
+	config, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		testForm(t, r, "grant_type", "refresh_token")
+		testForm(t, r, "refresh_token", "fake_refresh_token")
+		testForm(t, r, "client_id", fakeClientId)
+		testForm(t, r, "scope", "Files.ReadWrite offline_access")
+		writeJson(w, http.StatusOK, `{"access_token": "new_access_token", "token_type": "Bearer", "expires_in": 3600, "refresh_token": "new_refresh_token"}`)
+	})
+
+	store := NewMemoryStore(&Token{
+		AccessToken:  "old_access_token",
+		RefreshToken: "fake_refresh_token",
+		Expiry:       time.Now().Add(-time.Minute),
+	})
+	source := NewTokenSource(config, store)
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("RefreshTokenSource.Token returned error: %v", err)
+	}
+	if token.AccessToken != "new_access_token" || token.RefreshToken != "new_refresh_token" || !token.Valid() {
+		t.Errorf("RefreshTokenSource.Token returned %+v", token)
+	}
+	saved, _ := store.Load()
+	if saved.AccessToken != "new_access_token" {
+		t.Errorf("RefreshTokenSource.Token saved %+v, want the refreshed token", saved)
+	}
+}
+
+func TestRefreshTokenSource_Token_Valid(t *testing.T) {
+	config, _, teardown := setup()
+	defer teardown()
+
+	source := NewTokenSource(config, NewMemoryStore(&Token{
+		AccessToken: "fake_access_token",
+		Expiry:      time.Now().Add(time.Hour),
+	}))
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("RefreshTokenSource.Token returned error: %v", err)
+	}
+	if token.AccessToken != "fake_access_token" {
+		t.Errorf("RefreshTokenSource.Token returned %+v, want the stored token", token)
+	}
+}
+
+func TestRefreshTokenSource_Token_EmptyStore(t *testing.T) {
+	config, _, teardown := setup()
+	defer teardown()
+
+	_, err := NewTokenSource(config, NewMemoryStore(nil)).Token(context.Background())
+	if err != ErrNoToken {
+		t.Errorf("RefreshTokenSource.Token returned %v, want %v", err, ErrNoToken)
+	}
+}
+
+func TestRefreshTokenSource_Token_RefreshFailed(t *testing.T) {
+	config, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusBadRequest, `{"error": "invalid_grant", "error_description": "AADSTS70000: expired", "error_codes": [70000]}`)
+	})
+
+	source := NewTokenSource(config, NewMemoryStore(&Token{RefreshToken: "fake_refresh_token"}))
+	_, err := source.Token(context.Background())
+	var tokenError *TokenError
+	if !errors.As(err, &tokenError) {
+		t.Fatalf("RefreshTokenSource.Token returned %v, want *TokenError", err)
+	}
+	if tokenError.ErrorCode != "invalid_grant" || tokenError.StatusCode != http.StatusBadRequest {
+		t.Errorf("RefreshTokenSource.Token returned %+v", tokenError)
+	}
+}
+
+func TestRefreshTokenSource_Invalidate(t *testing.T) {
+	config, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, `{"access_token": "new_access_token", "expires_in": 3600}`)
+	})
+
+	old := &Token{AccessToken: "old_access_token", RefreshToken: "fake_refresh_token", Expiry: time.Now().Add(time.Hour)}
+	source := NewTokenSource(config, NewMemoryStore(old))
+	token, _ := source.Token(context.Background())
+	source.Invalidate(token)
+	token, err := source.Token(context.Background())
+	if err != nil {
+		t.Fatalf("RefreshTokenSource.Token returned error: %v", err)
+	}
+	if token.AccessToken != "new_access_token" || token.RefreshToken != "fake_refresh_token" {
+		t.Errorf("RefreshTokenSource.Token returned %+v", token)
+	}
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"net/http"
+)
+
+// Transport is an http.RoundTripper that authenticates requests with tokens
+// from Source. When the server answers 401 the token is invalidated and the
+// request is sent once more with a fresh token.
+type Transport struct {
+	Source TokenSource
+	// Base sends the requests. http.DefaultTransport is used when nil.
+	Base http.RoundTripper
+}
+
+// NewHttpClient returns an *http.Client that authenticates requests with tokens from source.
+func NewHttpClient(source TokenSource) *http.Client {
+	return &http.Client{
+		Transport: &Transport{
+			Source: source,
+		},
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+	resp, err := t.base().RoundTrip(t.authorize(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	source, ok := t.Source.(invalidator)
+	if !ok || !canReplay(req) {
+		return resp, nil
+	}
+	source.Invalidate(token)
+	newToken, err := t.Source.Token(req.Context())
+	if err != nil || newToken.AccessToken == token.AccessToken {
+		return resp, nil
+	}
+	retry, err := replay(req)
+	if err != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+	return t.base().RoundTrip(t.authorize(retry, newToken))
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) authorize(req *http.Request, token *Token) *http.Request {
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", token.authorizationHeader())
+	return authorized
+}
+
+func canReplay(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func replay(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retry.Body = body
+	return retry, nil
+}
+
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
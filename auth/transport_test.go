@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransport_RoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer fake_access_token" {
+			t.Errorf("Authorization: %q, want %q", got, "Bearer fake_access_token")
+		}
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(StaticTokenSource(&Token{AccessToken: "fake_access_token"}))
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Transport.RoundTrip returned error: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTransport_RoundTrip_RefreshOnUnauthorized(t *testing.T) {
+	config, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/fake_tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, `{"access_token": "new_access_token", "expires_in": 3600}`)
+	})
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "fake_body" {
+			t.Errorf("Request body: %q, want %q", body, "fake_body")
+		}
+		if r.Header.Get("Authorization") != "Bearer new_access_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	store := NewMemoryStore(&Token{AccessToken: "revoked_access_token", RefreshToken: "fake_refresh_token", Expiry: time.Now().Add(time.Hour)})
+	client := NewHttpClient(NewTokenSource(config, store))
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("fake_body"))
+	if err != nil {
+		t.Fatalf("Transport.RoundTrip returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Transport.RoundTrip returned status %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if calls != 2 {
+		t.Errorf("Server received %d requests, want 2", calls)
+	}
+}
@@ -1,3 +1,7 @@
 module github.com/bearcatat/onedrive-api
 
-go 1.21.0
+// Go 1.24 is the first version whose encoding/json supports the omitzero
+// option. The resources package relies on it to keep zero timestamps and
+// empty facets such as fileSystemInfo out of request bodies; older versions
+// ignore the option and send 0001-01-01T00:00:00Z instead.
+go 1.24
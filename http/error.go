@@ -1,7 +1,30 @@
 package http
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrThrottled     = errors.New("throttled")
+	ErrConflict      = errors.New("conflict")
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	ErrRangeIgnored  = errors.New("server ignored the requested range")
+)
+
+// Error codes returned by OneDrive drive API.
+const (
+	CodeAccessDenied         = "accessDenied"
+	CodeActivityLimitReached = "activityLimitReached"
+	CodeItemNotFound         = "itemNotFound"
+	CodeNameAlreadyExists    = "nameAlreadyExists"
+	CodeQuotaLimitReached    = "quotaLimitReached"
+	CodeResourceModified     = "resourceModified"
 )
 
 // ErrorResponse represents the error response returned by OneDrive drive API.
@@ -13,10 +36,7 @@ func (r *ErrorResponse) GetError() error {
 	if r == nil || r.Error == nil {
 		return nil
 	}
-	if r.Error.InnerError != nil {
-		return fmt.Errorf("%s-%s (%s)", r.Error.Code, r.Error.Message, r.Error.InnerError.Date)
-	}
-	return fmt.Errorf("%s-%s", r.Error.Code, r.Error.Message)
+	return newGraphErrorFromError(0, r.Error)
 }
 
 // Error represents the error in the response returned by OneDrive drive API.
@@ -28,8 +48,123 @@ type Error struct {
 }
 
 // InnerError represents the error details in the error returned by OneDrive drive API.
+// More specific error codes are nested in InnerError.
 type InnerError struct {
-	Date            string `json:"date"`
-	RequestId       string `json:"request-id"`
-	ClientRequestId string `json:"client-request-id"`
+	Code            string      `json:"code,omitempty"`
+	Date            string      `json:"date"`
+	RequestId       string      `json:"request-id"`
+	ClientRequestId string      `json:"client-request-id"`
+	InnerError      *InnerError `json:"innerError,omitempty"`
+}
+
+// GraphError is the error returned for every failed request. Use errors.As to
+// inspect it, or errors.Is with ErrNotFound, ErrThrottled, ErrConflict and
+// ErrQuotaExceeded.
+type GraphError struct {
+	StatusCode      int
+	Code            string
+	Message         string
+	InnerError      *InnerError
+	RequestId       string
+	ClientRequestId string
+	Date            string
+	// RetryAfter is the delay requested by the server, if any.
+	RetryAfter time.Duration
+}
+
+func newGraphErrorFromError(statusCode int, e *Error) *GraphError {
+	graphError := &GraphError{
+		StatusCode: statusCode,
+		Code:       e.Code,
+		Message:    e.Message,
+		InnerError: e.InnerError,
+	}
+	if e.InnerError != nil {
+		graphError.RequestId = e.InnerError.RequestId
+		graphError.ClientRequestId = e.InnerError.ClientRequestId
+		graphError.Date = e.InnerError.Date
+	}
+	return graphError
+}
+
+// newGraphError builds the error for a non-2xx response. body may be empty or
+// may not be JSON at all, in which case the status is used as the error code.
+func newGraphError(resp *http.Response, body []byte) *GraphError {
+	var graphError *GraphError
+	var errorResponse *ErrorResponse
+	if json.Unmarshal(body, &errorResponse) == nil && errorResponse != nil && errorResponse.Error != nil {
+		graphError = newGraphErrorFromError(resp.StatusCode, errorResponse.Error)
+	} else {
+		graphError = &GraphError{
+			StatusCode: resp.StatusCode,
+			Code:       strings.ReplaceAll(http.StatusText(resp.StatusCode), " ", ""),
+			Message:    strings.TrimSpace(string(body)),
+		}
+		if graphError.Code == "" {
+			graphError.Code = fmt.Sprintf("status%d", resp.StatusCode)
+		}
+	}
+	if graphError.RequestId == "" {
+		graphError.RequestId = resp.Header.Get("request-id")
+	}
+	if graphError.ClientRequestId == "" {
+		graphError.ClientRequestId = resp.Header.Get("client-request-id")
+	}
+	if graphError.Date == "" {
+		graphError.Date = resp.Header.Get("Date")
+	}
+	graphError.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"))
+	return graphError
+}
+
+func (e *GraphError) Error() string {
+	message := fmt.Sprintf("%s-%s", e.Code, e.Message)
+	if e.Date != "" {
+		message = fmt.Sprintf("%s (%s)", message, e.Date)
+	}
+	return message
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+func (e *GraphError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.hasCode(CodeItemNotFound)
+	case ErrThrottled:
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == 509 || e.hasCode(CodeActivityLimitReached)
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict || e.hasCode(CodeNameAlreadyExists)
+	case ErrQuotaExceeded:
+		return e.StatusCode == http.StatusInsufficientStorage || e.hasCode(CodeQuotaLimitReached)
+	}
+	return false
+}
+
+// hasCode reports whether code is the error code or one of the nested inner error codes.
+func (e *GraphError) hasCode(code string) bool {
+	if e.Code == code {
+		return true
+	}
+	for inner := e.InnerError; inner != nil; inner = inner.InnerError {
+		if inner.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+func IsThrottled(err error) bool {
+	return errors.Is(err, ErrThrottled)
+}
+
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+func IsQuotaExceeded(err error) bool {
+	return errors.Is(err, ErrQuotaExceeded)
 }
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestJsonResponse_GraphError(t *testing.T) {
+	client, serverURL, _, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{
+			"error": {
+				"code": "nameAlreadyExists",
+				"message": "Name already exists",
+				"innerError": {
+					"code": "fileAlreadyExists",
+					"date": "2025-01-31T00:00:00",
+					"request-id": "fake_request_id",
+					"client-request-id": "fake_client_request_id",
+					"innerError": {"code": "folderAlreadyExists"}
+				}
+			}
+		}`))
+	})
+	defer teardown()
+	client.SetRetryPolicy(nil)
+
+	err := client.DoRequestAndParseResponse(context.Background(), NewJsonRequest(http.MethodGet, serverURL, nil), nil)
+	var graphError *GraphError
+	if !errors.As(err, &graphError) {
+		t.Fatalf("HttpClient.DoRequestAndParseResponse returned %v, want *GraphError", err)
+	}
+	expected := &GraphError{
+		StatusCode: http.StatusConflict,
+		Code:       CodeNameAlreadyExists,
+		Message:    "Name already exists",
+		InnerError: &InnerError{
+			Code:            "fileAlreadyExists",
+			Date:            "2025-01-31T00:00:00",
+			RequestId:       "fake_request_id",
+			ClientRequestId: "fake_client_request_id",
+			InnerError:      &InnerError{Code: "folderAlreadyExists"},
+		},
+		RequestId:       "fake_request_id",
+		ClientRequestId: "fake_client_request_id",
+		Date:            "2025-01-31T00:00:00",
+	}
+	if !reflect.DeepEqual(graphError, expected) {
+		t.Errorf("HttpClient.DoRequestAndParseResponse returned %+v, want %+v", graphError, expected)
+	}
+	if !IsConflict(err) || IsNotFound(err) || IsThrottled(err) || IsQuotaExceeded(err) {
+		t.Errorf("GraphError %v matched the wrong sentinel errors", err)
+	}
+}
+
+func TestJsonResponse_GraphError_NonJsonBody(t *testing.T) {
+	client, serverURL, _, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "fake_request_id")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<html>Not Found</html>"))
+	})
+	defer teardown()
+
+	err := client.DoRequestAndParseResponse(context.Background(), NewJsonRequest(http.MethodGet, serverURL, nil), nil)
+	var graphError *GraphError
+	if !errors.As(err, &graphError) {
+		t.Fatalf("HttpClient.DoRequestAndParseResponse returned %v, want *GraphError", err)
+	}
+	if graphError.StatusCode != http.StatusNotFound || graphError.Code != "NotFound" || graphError.RequestId != "fake_request_id" {
+		t.Errorf("HttpClient.DoRequestAndParseResponse returned %+v", graphError)
+	}
+	if !IsNotFound(err) {
+		t.Errorf("IsNotFound(%v) returned false, want true", err)
+	}
+}
+
+func TestJsonResponse_GraphError_EmptyBody(t *testing.T) {
+	client, serverURL, _, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	defer teardown()
+	client.SetRetryPolicy(nil)
+
+	err := client.DoRequestAndParseResponse(context.Background(), NewJsonRequest(http.MethodDelete, serverURL, nil), nil)
+	var graphError *GraphError
+	if !errors.As(err, &graphError) {
+		t.Fatalf("HttpClient.DoRequestAndParseResponse returned %v, want *GraphError", err)
+	}
+	if !IsThrottled(err) || graphError.RetryAfter.Seconds() != 7 {
+		t.Errorf("HttpClient.DoRequestAndParseResponse returned %+v, want throttled error", graphError)
+	}
+}
+
+func TestHttpClient_Download_GraphError(t *testing.T) {
+	client, serverURL, _, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInsufficientStorage)
+		w.Write([]byte(`{"error": {"code": "quotaLimitReached", "message": "Insufficient Space Available"}}`))
+	})
+	defer teardown()
+	client.SetRetryPolicy(nil)
+
+	err := client.Download(context.Background(), NewJsonRequest(http.MethodGet, serverURL, nil), io.Discard)
+	if !IsQuotaExceeded(err) {
+		t.Errorf("HttpClient.Download returned %v, want quota exceeded error", err)
+	}
+}
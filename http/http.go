@@ -2,23 +2,36 @@ package http
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"net/http"
+	"time"
+)
+
+const (
+	maxErrorBodySize = 1 << 20
 )
 
 type HttpClientWithOauth2 struct {
 	oauth2Client *HttpClient
+	client       *HttpClient
 }
 
 func NewHttpClientWithOauth2(client *http.Client) *HttpClientWithOauth2 {
 	return &HttpClientWithOauth2{
 		oauth2Client: NewHttpClient(client),
+		client:       NewHttpClient(&http.Client{}),
 	}
 }
 
+// SetRetryPolicy sets the retry policy for both authenticated and
+// unauthenticated requests. A nil policy disables retries.
+func (c *HttpClientWithOauth2) SetRetryPolicy(policy *RetryPolicy) {
+	c.oauth2Client.SetRetryPolicy(policy)
+	c.client.SetRetryPolicy(policy)
+}
+
 func (c *HttpClientWithOauth2) DoWithoutAuth(ctx context.Context, req Request, target interface{}) error {
-	return NewHttpClient(&http.Client{}).DoRequestAndParseResponse(ctx, req, target)
+	return c.client.DoRequestAndParseResponse(ctx, req, target)
 }
 
 func (c *HttpClientWithOauth2) DoWithAuth(ctx context.Context, req Request, target interface{}) error {
@@ -29,16 +42,41 @@ func (c *HttpClientWithOauth2) Download(ctx context.Context, req Request, writer
 	return c.oauth2Client.Download(ctx, req, writer)
 }
 
+func (c *HttpClientWithOauth2) DownloadRange(ctx context.Context, req Request, writer io.Writer) error {
+	return c.oauth2Client.DownloadRange(ctx, req, writer)
+}
+
+// DownloadWithoutAuth downloads from a pre-authenticated URL, such as a
+// download URL, without sending the OAuth token.
+func (c *HttpClientWithOauth2) DownloadWithoutAuth(ctx context.Context, req Request, writer io.Writer) error {
+	return c.client.Download(ctx, req, writer)
+}
+
+// DownloadRangeWithoutAuth downloads a range from a pre-authenticated URL
+// without sending the OAuth token.
+func (c *HttpClientWithOauth2) DownloadRangeWithoutAuth(ctx context.Context, req Request, writer io.Writer) error {
+	return c.client.DownloadRange(ctx, req, writer)
+}
+
 type HttpClient struct {
-	client *http.Client
+	client      *http.Client
+	retryPolicy *RetryPolicy
+	sleep       func(ctx context.Context, d time.Duration) error
 }
 
 func NewHttpClient(client *http.Client) *HttpClient {
 	return &HttpClient{
-		client: client,
+		client:      client,
+		retryPolicy: DefaultRetryPolicy(),
+		sleep:       Sleep,
 	}
 }
 
+// SetRetryPolicy sets the retry policy. A nil policy disables retries.
+func (c *HttpClient) SetRetryPolicy(policy *RetryPolicy) {
+	c.retryPolicy = policy
+}
+
 func (c *HttpClient) DoRequestAndParseResponse(ctx context.Context, req Request, target interface{}) error {
 	resp, err := c.do(ctx, req)
 	if err != nil {
@@ -49,29 +87,60 @@ func (c *HttpClient) DoRequestAndParseResponse(ctx context.Context, req Request,
 }
 
 func (c *HttpClient) Download(ctx context.Context, req Request, writer io.Writer) error {
+	return c.download(ctx, req, writer, false)
+}
+
+// DownloadRange downloads the range requested by the Range header of req. It
+// fails with ErrRangeIgnored when the server sends the whole content instead.
+func (c *HttpClient) DownloadRange(ctx context.Context, req Request, writer io.Writer) error {
+	return c.download(ctx, req, writer, true)
+}
+
+func (c *HttpClient) download(ctx context.Context, req Request, writer io.Writer, partial bool) error {
 	resp, err := c.do(ctx, req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return newGraphError(resp, body)
+	}
+	if partial && resp.StatusCode != http.StatusPartialContent {
+		return ErrRangeIgnored
 	}
 	_, err = io.Copy(writer, resp.Body)
 	return err
 }
 
+// do sends the request, retrying throttled responses, server errors and
+// connection failures according to the retry policy. The request is rebuilt
+// for every attempt so that its body can be replayed.
 func (c *HttpClient) do(ctx context.Context, request Request) (*http.Response, error) {
-	httpRequest, err := request.GetHttpRequest()
-	if err != nil {
-		return nil, err
-	}
-	httpRequest = httpRequest.WithContext(ctx)
-	resp, err := c.client.Do(httpRequest)
-	if err != nil {
-		return nil, c.handleError(ctx, err)
+	for attempt := 0; ; attempt++ {
+		httpRequest, err := request.GetHttpRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.client.Do(httpRequest.WithContext(ctx))
+		if err != nil {
+			if ctx.Err() != nil || !isRetryableError(httpRequest.Method, err) || !c.retryPolicy.canRetry(attempt) {
+				return nil, c.handleError(ctx, err)
+			}
+			if err := c.sleep(ctx, c.retryPolicy.delay(attempt, nil)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !isRetryableStatus(resp.StatusCode) || !c.retryPolicy.canRetry(attempt) {
+			return resp, nil
+		}
+		delay := c.retryPolicy.delay(attempt, resp)
+		drainAndClose(resp.Body)
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
 	}
-	return resp, nil
 }
 
 func (c *HttpClient) handleError(ctx context.Context, err error) error {
@@ -0,0 +1,277 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type fakeTarget struct {
+	Name string `json:"name"`
+}
+
+func setup(handler http.HandlerFunc) (client *HttpClient, serverURL *url.URL, sleeps *[]time.Duration, teardown func()) {
+	server := httptest.NewServer(handler)
+	serverURL, _ = url.Parse(server.URL)
+	client = NewHttpClient(&http.Client{})
+	sleeps = &[]time.Duration{}
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		return ctx.Err()
+	}
+	return client, serverURL, sleeps, server.Close
+}
+
+func TestHttpClient_Retry_RetryAfter(t *testing.T) {
+	var calls int32
+	client, serverURL, sleeps, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"name": "fake_name"}`))
+	})
+	defer teardown()
+
+	var target *fakeTarget
+	err := client.DoRequestAndParseResponse(context.Background(), NewJsonRequest(http.MethodGet, serverURL, nil), &target)
+	if err != nil {
+		t.Errorf("HttpClient.DoRequestAndParseResponse returned error: %v", err)
+	}
+	if target == nil || target.Name != "fake_name" {
+		t.Errorf("HttpClient.DoRequestAndParseResponse returned %+v, want fake_name", target)
+	}
+	expectedSleeps := []time.Duration{3 * time.Second, 3 * time.Second}
+	if !reflect.DeepEqual(*sleeps, expectedSleeps) {
+		t.Errorf("HttpClient slept %v, want %v", *sleeps, expectedSleeps)
+	}
+}
+
+func TestHttpClient_Retry_ExponentialBackoff(t *testing.T) {
+	client, serverURL, sleeps, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer teardown()
+	client.SetRetryPolicy(&RetryPolicy{MaxRetries: 3, MinBackoff: time.Second, MaxBackoff: 3 * time.Second})
+
+	resp, err := client.do(context.Background(), NewJsonRequest(http.MethodGet, serverURL, nil))
+	if err != nil {
+		t.Fatalf("HttpClient.do returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("HttpClient.do returned status %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if len(*sleeps) != 3 {
+		t.Fatalf("HttpClient slept %d times, want 3", len(*sleeps))
+	}
+	// Equal jitter: each delay is between half and all of 1s, 2s and 3s.
+	bounds := [][2]time.Duration{{500 * time.Millisecond, time.Second}, {time.Second, 2 * time.Second}, {1500 * time.Millisecond, 3 * time.Second}}
+	for i, d := range *sleeps {
+		if d < bounds[i][0] || d > bounds[i][1] {
+			t.Errorf("HttpClient sleep %d was %v, want between %v and %v", i, d, bounds[i][0], bounds[i][1])
+		}
+	}
+}
+
+func TestHttpClient_Retry_ReplaysJsonBody(t *testing.T) {
+	var calls int32
+	client, serverURL, _, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"fake_name"}` {
+			t.Errorf("Request body: %s, want %s", body, `{"name":"fake_name"}`)
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer teardown()
+
+	err := client.DoRequestAndParseResponse(context.Background(), NewJsonRequest(http.MethodPost, serverURL, &fakeTarget{Name: "fake_name"}), nil)
+	if err != nil {
+		t.Errorf("HttpClient.DoRequestAndParseResponse returned error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Server received %d requests, want 2", calls)
+	}
+}
+
+func TestHttpClient_Retry_ReplaysFileFragment(t *testing.T) {
+	var calls int32
+	client, serverURL, _, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "fragment" {
+			t.Errorf("Request body: %s, want fragment", body)
+		}
+		if got := r.Header.Get("Content-Range"); got != "bytes 8-15/16" {
+			t.Errorf("Content-Range: %s, want bytes 8-15/16", got)
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"name": "fake_name"}`))
+	})
+	defer teardown()
+
+	var target *fakeTarget
+	err := client.DoRequestAndParseResponse(context.Background(), NewFileFragmentUploadRequest(*serverURL, 8, 16, []byte("fragment")), &target)
+	if err != nil {
+		t.Errorf("HttpClient.DoRequestAndParseResponse returned error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Server received %d requests, want 2", calls)
+	}
+}
+
+func TestHttpClient_Retry_ConnectionReset(t *testing.T) {
+	var calls int32
+	client, serverURL, sleeps, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Hijack failed: %v", err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{"name": "fake_name"}`))
+	})
+	defer teardown()
+
+	var target *fakeTarget
+	err := client.DoRequestAndParseResponse(context.Background(), NewJsonRequest(http.MethodGet, serverURL, nil), &target)
+	if err != nil {
+		t.Errorf("HttpClient.DoRequestAndParseResponse returned error: %v", err)
+	}
+	if len(*sleeps) != 1 {
+		t.Errorf("HttpClient slept %d times, want 1", len(*sleeps))
+	}
+}
+
+func TestHttpClient_Retry_ConnectionReset_Post(t *testing.T) {
+	var calls int32
+	client, serverURL, sleeps, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack failed: %v", err)
+			return
+		}
+		conn.Close()
+	})
+	defer teardown()
+
+	err := client.DoRequestAndParseResponse(context.Background(), NewJsonRequest(http.MethodPost, serverURL, &fakeTarget{Name: "fake_name"}), nil)
+	if err == nil {
+		t.Errorf("HttpClient.DoRequestAndParseResponse returned no error")
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 1 || len(*sleeps) != 0 {
+		t.Errorf("Server received %d requests after %d sleeps, want 1 request and no sleep", calls, len(*sleeps))
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		method string
+		err    error
+		want   bool
+	}{
+		{http.MethodGet, syscall.ECONNRESET, true},
+		{http.MethodPut, io.ErrUnexpectedEOF, true},
+		{http.MethodDelete, io.EOF, true},
+		{http.MethodPost, syscall.ECONNRESET, false},
+		{http.MethodPatch, io.EOF, false},
+		{http.MethodPost, syscall.ECONNREFUSED, true},
+		{http.MethodGet, errors.New("fake error"), false},
+	}
+	for _, tt := range tests {
+		if got := isRetryableError(tt.method, tt.err); got != tt.want {
+			t.Errorf("isRetryableError(%s, %v) returned %v, want %v", tt.method, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestHttpClient_Retry_NoRetryPolicy(t *testing.T) {
+	var calls int32
+	client, serverURL, _, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	defer teardown()
+	client.SetRetryPolicy(NoRetryPolicy())
+
+	resp, err := client.do(context.Background(), NewJsonRequest(http.MethodGet, serverURL, nil))
+	if err != nil {
+		t.Fatalf("HttpClient.do returned error: %v", err)
+	}
+	resp.Body.Close()
+	if calls != 1 {
+		t.Errorf("Server received %d requests, want 1", calls)
+	}
+}
+
+func TestHttpClient_Retry_ContextCanceled(t *testing.T) {
+	client, serverURL, _, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	defer teardown()
+	client.sleep = Sleep
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := client.DoRequestAndParseResponse(ctx, NewJsonRequest(http.MethodGet, serverURL, nil), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("HttpClient.DoRequestAndParseResponse returned %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	d, ok := parseRetryAfter(time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat))
+	if !ok || d <= 0 || d > 10*time.Second {
+		t.Errorf("parseRetryAfter returned %v, %v, want a delay up to 10s", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Errorf("parseRetryAfter accepted an invalid value")
+	}
+}
+
+func TestHttpClient_DownloadRange(t *testing.T) {
+	client, serverURL, _, teardown := setup(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "bytes=6-" {
+			w.Write([]byte("hello world!"))
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte("world!"))
+	})
+	defer teardown()
+
+	writer := &bytes.Buffer{}
+	req := WithHeader(NewJsonRequest(http.MethodGet, serverURL, nil), "Range", "bytes=6-")
+	err := client.DownloadRange(context.Background(), req, writer)
+	if err != nil || writer.String() != "world!" {
+		t.Errorf("HttpClient.DownloadRange returned %q, %v, want %q, nil", writer.String(), err, "world!")
+	}
+
+	writer.Reset()
+	err = client.DownloadRange(context.Background(), NewJsonRequest(http.MethodGet, serverURL, nil), writer)
+	if err != ErrRangeIgnored || writer.Len() != 0 {
+		t.Errorf("HttpClient.DownloadRange returned %q, %v, want nothing written and %v", writer.String(), err, ErrRangeIgnored)
+	}
+}
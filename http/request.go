@@ -64,6 +64,8 @@ type FileFragmentUploadReqeust struct {
 	body  []byte
 }
 
+// NewFileFragmentUploadRequest returns a request sending body at start of a
+// file of total bytes. A negative total sends the unknown length form "*".
 func NewFileFragmentUploadRequest(url url.URL, start, total int64, body []byte) Request {
 	return &FileFragmentUploadReqeust{
 		url:   url,
@@ -81,6 +83,85 @@ func (r *FileFragmentUploadReqeust) GetHttpRequest() (*http.Request, error) {
 	}
 	req.Header.Set("Content-Length", strconv.FormatInt(reader.Size(), 10))
 	end := r.start + reader.Size() - 1
-	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", r.start, end, r.total))
+	total := "*"
+	if r.total >= 0 {
+		total = strconv.FormatInt(r.total, 10)
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%s", r.start, end, total))
+	return req, nil
+}
+
+// ContentRequest sends raw bytes, e.g. the content of a small file.
+type ContentRequest struct {
+	method string
+	url    *url.URL
+	body   []byte
+}
+
+func NewContentRequest(method string, url *url.URL, body []byte) Request {
+	return &ContentRequest{
+		method: method,
+		url:    url,
+		body:   body,
+	}
+}
+
+func (r *ContentRequest) GetHttpRequest() (*http.Request, error) {
+	req, err := http.NewRequest(r.method, r.url.String(), bytes.NewReader(r.body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	return req, nil
+}
+
+// headerRequest adds a header to the request it wraps.
+type headerRequest struct {
+	Request
+	key   string
+	value string
+}
+
+// WithHeader returns req with the header key set to value.
+func WithHeader(req Request, key, value string) Request {
+	return &headerRequest{
+		Request: req,
+		key:     key,
+		value:   value,
+	}
+}
+
+func (r *headerRequest) GetHttpRequest() (*http.Request, error) {
+	req, err := r.Request.GetHttpRequest()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(r.key, r.value)
+	return req, nil
+}
+
+// bodyRequest passes the body of the request it wraps through a reader.
+type bodyRequest struct {
+	Request
+	wrap func(io.Reader) io.Reader
+}
+
+// WithBodyReader returns req with its body read through wrap, e.g. to meter or
+// throttle an upload. The content length of the body is kept.
+func WithBodyReader(req Request, wrap func(io.Reader) io.Reader) Request {
+	return &bodyRequest{
+		Request: req,
+		wrap:    wrap,
+	}
+}
+
+func (r *bodyRequest) GetHttpRequest() (*http.Request, error) {
+	req, err := r.Request.GetHttpRequest()
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = io.NopCloser(r.wrap(req.Body))
+	}
 	return req, nil
 }
@@ -50,14 +50,10 @@ func (r *JsonResponse) unMarshal(target interface{}) error {
 }
 
 func (r *JsonResponse) unmarshalError() error {
-	if len(r.body) == 0 {
+	if r.response.StatusCode < http.StatusBadRequest {
 		return nil
 	}
-	var errorResponse *ErrorResponse
-	if err := json.Unmarshal(r.body, &errorResponse); err != nil {
-		return err
-	}
-	return errorResponse.GetError()
+	return newGraphError(r.response, r.body)
 }
 
 func (r *JsonResponse) unmarshalBodyToTarget(target interface{}) error {
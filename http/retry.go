@@ -0,0 +1,168 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 5
+	defaultMinBackoff = 1 * time.Second
+	defaultMaxBackoff = 60 * time.Second
+)
+
+// RetryPolicy describes how HttpClient retries throttled and failed requests.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. Zero disables retries.
+	MaxRetries int
+	// MinBackoff is the delay before the first retry when the server does not
+	// send Retry-After. Each delay is randomized to between half and all of
+	// its value.
+	MinBackoff time.Duration
+	// MaxBackoff caps the exponential backoff delay. It does not cap the
+	// delay of a Retry-After header: retrying earlier than the server asks
+	// keeps a throttled client throttled. Use the context to bound the wait.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewHttpClient.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: defaultMaxRetries,
+		MinBackoff: defaultMinBackoff,
+		MaxBackoff: defaultMaxBackoff,
+	}
+}
+
+// NoRetryPolicy returns a policy that sends each request exactly once.
+func NoRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{}
+}
+
+func (p *RetryPolicy) canRetry(attempt int) bool {
+	return p != nil && attempt < p.MaxRetries
+}
+
+// backoff returns the delay before retry number attempt (starting at 0),
+// using exponential backoff with equal jitter: a random delay between half
+// and all of the exponential delay, so retries spread out without coming
+// back too soon.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	if p.MinBackoff <= 0 {
+		return 0
+	}
+	delay := p.MinBackoff
+	for i := 0; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// delay returns how long to wait before retrying after resp. Retry-After is
+// honored when present, even beyond MaxBackoff, otherwise the exponential
+// backoff is used.
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+	return p.backoff(attempt)
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		d := time.Until(date)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+		509: // Bandwidth Limit Exceeded, returned by OneDrive for Business
+		return true
+	}
+	return false
+}
+
+// isRetryableError reports whether a request sent with method and failing
+// with err can be sent again. A refused connection never reached the server
+// and is retried for any method. Other connection failures may happen after
+// the server processed the request, so they are only retried for idempotent
+// methods: sending a POST again could e.g. create a second folder.
+func isRetryableError(method string, err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	if !isIdempotent(method) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isIdempotent reports whether sending a request with method twice has the
+// same effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// Sleep waits for d or until ctx is done, returning the error of ctx in the
+// latter case.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, 1<<16))
+	body.Close()
+}
@@ -2,6 +2,7 @@ package onedrive
 
 import (
 	"context"
+	"iter"
 	http2 "net/http"
 	"net/url"
 
@@ -33,6 +34,18 @@ func (c *Children) HasNext() bool {
 	return c.raw.NextURL != ""
 }
 
+// SkipToken returns the token of the next page, to continue the listing later
+// with QueryOptions.SkipToken, or "" on the last page.
+func (c *Children) SkipToken() string {
+	next, err := url.Parse(c.raw.NextURL)
+	if err != nil {
+		return ""
+	}
+	return next.Query().Get("$skiptoken")
+}
+
+// Next fetches the next page. The next link keeps the query options of the
+// first page.
 func (c *Children) Next(ctx context.Context) (*Children, error) {
 	if !c.HasNext() {
 		return nil, ErrChildrenNoNext
@@ -51,3 +64,139 @@ func (c *Children) nextRequest() http.Request {
 	url, _ := url.Parse(c.raw.NextURL)
 	return http.NewJsonRequest(http2.MethodGet, url, nil)
 }
+
+// ChildrenOptions configures the listing of the children of a folder. A nil
+// *ChildrenOptions lists with the defaults.
+type ChildrenOptions struct {
+	// PageSize is the largest number of children fetched per request. The
+	// server picks the size of the pages when it is 0. It is ignored when
+	// Query sets Top.
+	PageSize int
+	// Query selects, filters and sorts the children. The options apply to
+	// every page.
+	Query *QueryOptions
+}
+
+// query returns the query options of the first page.
+func (o *ChildrenOptions) query() *QueryOptions {
+	if o.PageSize <= 0 || (o.Query != nil && o.Query.top > 0) {
+		return o.Query
+	}
+	query := Query()
+	if o.Query != nil {
+		*query = *o.Query
+	}
+	return query.Top(o.PageSize)
+}
+
+// ChildrenIterator lists the children of a folder, fetching a page at a time
+// as the children are consumed:
+//
+//	it := folder.Children(ctx, nil)
+//	for it.Next() {
+//		fmt.Println(it.Item().Name)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type ChildrenIterator struct {
+	ctx     context.Context
+	parent  *DriveItem
+	options *ChildrenOptions
+
+	page  *Children
+	index int
+	item  *DriveItem
+	err   error
+	done  bool
+}
+
+// Children returns an iterator over the children of the folder. No request
+// is made until the first call to Next.
+func (i *DriveItem) Children(ctx context.Context, opts ...*ChildrenOptions) *ChildrenIterator {
+	options := &ChildrenOptions{}
+	if len(opts) > 0 && opts[0] != nil {
+		options = opts[0]
+	}
+	return &ChildrenIterator{ctx: ctx, parent: i, options: options}
+}
+
+// Next advances to the next child, fetching the next page when the current
+// one is consumed. It returns false when there are no more children or an
+// error occurred, which Err returns.
+func (it *ChildrenIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	for it.page == nil || it.index >= len(it.page.Value) {
+		if it.err = it.ctx.Err(); it.err == nil {
+			it.err = it.fetch()
+		}
+		if it.err != nil || it.page == nil {
+			it.done = true
+			it.item = nil
+			return false
+		}
+	}
+	it.item = it.page.Value[it.index]
+	it.index++
+	return true
+}
+
+// fetch fetches the first or the next page, leaving page nil after the last.
+func (it *ChildrenIterator) fetch() error {
+	var page *Children
+	var err error
+	switch {
+	case it.page == nil && it.index == 0:
+		page, err = it.parent.listChildren(it.ctx, it.options)
+	case it.page.HasNext():
+		page, err = it.page.Next(it.ctx)
+	}
+	it.page = page
+	it.index = 0
+	return err
+}
+
+// Item returns the current child.
+func (it *ChildrenIterator) Item() *DriveItem {
+	return it.item
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ChildrenIterator) Err() error {
+	return it.err
+}
+
+// All returns the remaining children as a sequence for range. An error
+// ends the sequence as a nil item with the error. Breaking out of the loop
+// stops fetching pages.
+func (it *ChildrenIterator) All() iter.Seq2[*DriveItem, error] {
+	return func(yield func(*DriveItem, error) bool) {
+		for it.Next() {
+			if !yield(it.Item(), nil) {
+				return
+			}
+		}
+		if it.err != nil {
+			yield(nil, it.err)
+		}
+	}
+}
+
+// ListAll returns all the children of the folder, up to limit. When the
+// folder has more than limit children it returns the first limit children
+// and ErrTooManyChildren. A limit of 0 or less lists all children.
+func (i *DriveItem) ListAll(ctx context.Context, limit int, opts ...*ChildrenOptions) ([]*DriveItem, error) {
+	var items []*DriveItem
+	for item, err := range i.Children(ctx, opts...).All() {
+		if err != nil {
+			return nil, err
+		}
+		if limit > 0 && len(items) == limit {
+			return items, ErrTooManyChildren
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -2,6 +2,8 @@ package onedrive
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"reflect"
 	"testing"
@@ -46,3 +48,134 @@ func TestChildren_Next_NoNext(t *testing.T) {
 		t.Errorf("Children.Next returned %+v, want %+v", err, ErrChildrenNoNext)
 	}
 }
+
+// setup_children_iterator returns a folder holding the files 0.txt to
+// (count-1).txt, listed in pages of 2 children.
+func setup_children_iterator(t *testing.T, count int) (folder *DriveItem, fake *fakeDrive, teardown func()) {
+	files := map[string]string{"folder/": ""}
+	for i := range count {
+		files[fmt.Sprintf("folder/%d.txt", i)] = ""
+	}
+	drive, fake, teardown := setup_fake_drive(t, files)
+	folder, err := drive.GetByPath(context.Background(), "folder")
+	if err != nil {
+		t.Fatalf("Drive.GetByPath returned error: %v", err)
+	}
+	return folder, fake, teardown
+}
+
+func TestDriveItem_Children(t *testing.T) {
+	folder, fake, teardown := setup_children_iterator(t, 5)
+	defer teardown()
+
+	requests := fake.requests
+	it := folder.Children(context.Background())
+	if fake.requests != requests {
+		t.Errorf("DriveItem.Children sent %d requests before Next, want none", fake.requests-requests)
+	}
+	var names []string
+	for it.Next() {
+		names = append(names, it.Item().Name)
+	}
+	if it.Err() != nil {
+		t.Errorf("ChildrenIterator.Err returned %v", it.Err())
+	}
+	if fmt.Sprint(names) != "[0.txt 1.txt 2.txt 3.txt 4.txt]" {
+		t.Errorf("ChildrenIterator listed %v", names)
+	}
+	if fake.requests-requests != 3 {
+		t.Errorf("ChildrenIterator sent %d requests, want 3 pages", fake.requests-requests)
+	}
+	if it.Next() || it.Item() != nil {
+		t.Errorf("ChildrenIterator.Next after the last child returned true")
+	}
+}
+
+func TestDriveItem_Children_PageSize(t *testing.T) {
+	folder, fake, teardown := setup_children_iterator(t, 5)
+	defer teardown()
+
+	requests := fake.requests
+	items, err := folder.ListAll(context.Background(), 0, &ChildrenOptions{PageSize: 4})
+	if err != nil || len(items) != 5 {
+		t.Errorf("DriveItem.ListAll returned %d items, %v, want 5", len(items), err)
+	}
+	if fake.requests-requests != 2 {
+		t.Errorf("DriveItem.ListAll sent %d requests, want 2 pages", fake.requests-requests)
+	}
+}
+
+func TestChildrenIterator_All(t *testing.T) {
+	folder, fake, teardown := setup_children_iterator(t, 5)
+	defer teardown()
+
+	requests := fake.requests
+	var names []string
+	for item, err := range folder.Children(context.Background()).All() {
+		if err != nil {
+			t.Fatalf("ChildrenIterator.All returned error: %v", err)
+		}
+		names = append(names, item.Name)
+		if len(names) == 3 {
+			break
+		}
+	}
+	if fmt.Sprint(names) != "[0.txt 1.txt 2.txt]" {
+		t.Errorf("ChildrenIterator.All listed %v", names)
+	}
+	if fake.requests-requests != 2 {
+		t.Errorf("ChildrenIterator.All sent %d requests, want 2 pages", fake.requests-requests)
+	}
+}
+
+func TestChildrenIterator_Canceled(t *testing.T) {
+	folder, _, teardown := setup_children_iterator(t, 5)
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := folder.Children(ctx)
+	count := 0
+	for it.Next() {
+		count++
+		cancel()
+	}
+	if count != 2 {
+		t.Errorf("ChildrenIterator listed %d children after cancel, want the 2 of the first page", count)
+	}
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("ChildrenIterator.Err returned %v, want %v", it.Err(), context.Canceled)
+	}
+
+	var last error
+	for _, err := range folder.Children(ctx).All() {
+		last = err
+	}
+	if !errors.Is(last, context.Canceled) {
+		t.Errorf("ChildrenIterator.All ended with %v, want %v", last, context.Canceled)
+	}
+}
+
+func TestDriveItem_ListAll(t *testing.T) {
+	folder, fake, teardown := setup_children_iterator(t, 5)
+	defer teardown()
+
+	items, err := folder.ListAll(context.Background(), 0)
+	if err != nil || len(items) != 5 {
+		t.Errorf("DriveItem.ListAll returned %d items, %v, want 5", len(items), err)
+	}
+
+	requests := fake.requests
+	items, err = folder.ListAll(context.Background(), 3)
+	if err != ErrTooManyChildren || len(items) != 3 || items[2].Name != "2.txt" {
+		t.Errorf("DriveItem.ListAll returned %d items, %v, want 3 and %v", len(items), err, ErrTooManyChildren)
+	}
+	if fake.requests-requests != 2 {
+		t.Errorf("DriveItem.ListAll sent %d requests, want 2 pages", fake.requests-requests)
+	}
+
+	items, err = folder.ListAll(context.Background(), 5)
+	if err != nil || len(items) != 5 {
+		t.Errorf("DriveItem.ListAll returned %d items, %v, want 5", len(items), err)
+	}
+}
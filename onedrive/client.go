@@ -3,7 +3,9 @@ package onedrive
 import (
 	"context"
 	http2 "net/http"
+	"net/url"
 
+	"github.com/bearcatat/onedrive-api/auth"
 	"github.com/bearcatat/onedrive-api/http"
 	"github.com/bearcatat/onedrive-api/resources"
 )
@@ -11,6 +13,9 @@ import (
 type core struct {
 	client *http.HttpClientWithOauth2
 	url    *oneDriveURL
+
+	uploadLimiter   *RateLimiter
+	downloadLimiter *RateLimiter
 }
 
 func newCore(client *http2.Client) *core {
@@ -30,8 +35,38 @@ func NewClient(client *http2.Client) *Client {
 	}
 }
 
+// NewClientWithTokenSource returns a client that authenticates requests with
+// tokens from source, refreshing them as needed.
+func NewClientWithTokenSource(source auth.TokenSource) *Client {
+	return NewClient(auth.NewHttpClient(source))
+}
+
 func (c *Client) GetMyDrive(ctx context.Context) (*Drive, error) {
-	req := http.NewJsonRequest(http2.MethodGet, c.url.GetMyDrive(), nil)
+	return c.getDrive(ctx, c.url.GetMyDrive())
+}
+
+// GetUserDrive returns the OneDrive of a user, identified by id or user principal name.
+// Unlike GetMyDrive it works with app-only tokens.
+func (c *Client) GetUserDrive(ctx context.Context, userIdOrUPN string) (*Drive, error) {
+	return c.getDrive(ctx, c.url.GetUserDrive(userIdOrUPN))
+}
+
+func (c *Client) GetDriveByID(ctx context.Context, driveId string) (*Drive, error) {
+	return c.getDrive(ctx, c.url.GetDrive(driveId))
+}
+
+// GetGroupDrive returns the document library of a Microsoft 365 group.
+func (c *Client) GetGroupDrive(ctx context.Context, groupId string) (*Drive, error) {
+	return c.getDrive(ctx, c.url.GetGroupDrive(groupId))
+}
+
+// GetSiteDrive returns the default document library of a SharePoint site.
+func (c *Client) GetSiteDrive(ctx context.Context, siteId string) (*Drive, error) {
+	return c.getDrive(ctx, c.url.GetSiteDrive(siteId))
+}
+
+func (c *Client) getDrive(ctx context.Context, url *url.URL) (*Drive, error) {
+	req := http.NewJsonRequest(http2.MethodGet, url, nil)
 	var drive *resources.Drive
 	err := c.client.DoWithAuth(ctx, req, &drive)
 	if err != nil {
@@ -39,3 +74,48 @@ func (c *Client) GetMyDrive(ctx context.Context) (*Drive, error) {
 	}
 	return newDrive(c.core, drive), nil
 }
+
+// SetRetryPolicy sets how requests are retried when Graph throttles them or
+// fails with a server error. A nil policy disables retries.
+func (c *Client) SetRetryPolicy(policy *http.RetryPolicy) {
+	c.client.SetRetryPolicy(policy)
+}
+
+// SetUploadRateLimiter limits the bandwidth of all uploads of the client, in
+// addition to the limiter of each upload. A nil limiter removes the limit.
+func (c *Client) SetUploadRateLimiter(limiter *RateLimiter) {
+	c.uploadLimiter = limiter
+}
+
+// SetDownloadRateLimiter limits the bandwidth of all downloads of the client,
+// in addition to the limiter of each download. A nil limiter removes the limit.
+func (c *Client) SetDownloadRateLimiter(limiter *RateLimiter) {
+	c.downloadLimiter = limiter
+}
+
+func (c *Client) ListMyDrives(ctx context.Context) (*Drives, error) {
+	return c.listDrives(ctx, c.url.ListMyDrives())
+}
+
+func (c *Client) ListUserDrives(ctx context.Context, userIdOrUPN string) (*Drives, error) {
+	return c.listDrives(ctx, c.url.ListUserDrives(userIdOrUPN))
+}
+
+// ListSiteDrives returns the document libraries of a SharePoint site.
+func (c *Client) ListSiteDrives(ctx context.Context, siteId string) (*Drives, error) {
+	return c.listDrives(ctx, c.url.ListSiteDrives(siteId))
+}
+
+func (c *Client) ListGroupDrives(ctx context.Context, groupId string) (*Drives, error) {
+	return c.listDrives(ctx, c.url.ListGroupDrives(groupId))
+}
+
+func (c *Client) listDrives(ctx context.Context, url *url.URL) (*Drives, error) {
+	req := http.NewJsonRequest(http2.MethodGet, url, nil)
+	var drives *resources.Drives
+	err := c.client.DoWithAuth(ctx, req, &drives)
+	if err != nil {
+		return nil, err
+	}
+	return newDrives(c.core, drives), nil
+}
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/bearcatat/onedrive-api/auth"
 	"github.com/bearcatat/onedrive-api/resources"
 )
 
@@ -37,3 +38,109 @@ func TestClient_GetMyDrive(t *testing.T) {
 		t.Errorf("Client.GetMyDrive returned %+v, want %+v", drive.Drive, expectedDrive)
 	}
 }
+
+func TestNewClientWithTokenSource(t *testing.T) {
+	url, mux, teardown := setup()
+	defer teardown()
+	client := NewClientWithTokenSource(auth.StaticTokenSource(&auth.Token{AccessToken: "fake_access_token"}))
+	client.url.baseURL = url
+
+	mux.HandleFunc("/me/drive", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		testHeader(t, r, "Authorization", "Bearer fake_access_token")
+		jsonData := readFile(t, "fake_drive.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	_, err := client.GetMyDrive(context.Background())
+	if err != nil {
+		t.Errorf("Client.GetMyDrive returned error: %v", err)
+	}
+}
+
+func TestClient_GetDrive(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		getDrive func(ctx context.Context, client *Client) (*Drive, error)
+	}{
+		{"GetUserDrive", "/users/user@example.com/drive", func(ctx context.Context, client *Client) (*Drive, error) {
+			return client.GetUserDrive(ctx, "user@example.com")
+		}},
+		{"GetDriveByID", "/drives/fake_drive_id", func(ctx context.Context, client *Client) (*Drive, error) {
+			return client.GetDriveByID(ctx, "fake_drive_id")
+		}},
+		{"GetGroupDrive", "/groups/fake_group_id/drive", func(ctx context.Context, client *Client) (*Drive, error) {
+			return client.GetGroupDrive(ctx, "fake_group_id")
+		}},
+		{"GetSiteDrive", "/sites/example.sharepoint.com,fake_site_id,fake_web_id/drive", func(ctx context.Context, client *Client) (*Drive, error) {
+			return client.GetSiteDrive(ctx, "example.sharepoint.com,fake_site_id,fake_web_id")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, teardown := setup_client()
+			defer teardown()
+
+			mux.HandleFunc(tt.path, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				jsonData := readFile(t, "fake_drive.json")
+				fmt.Fprint(w, string(jsonData))
+			})
+
+			drive, err := tt.getDrive(context.Background(), client)
+			if err != nil {
+				t.Fatalf("Client.%s returned error: %v", tt.name, err)
+			}
+			expectedDrive := getDataFromFile[*resources.Drive](t, "fake_drive.json")
+			if !reflect.DeepEqual(drive.Drive, expectedDrive) {
+				t.Errorf("Client.%s returned %+v, want %+v", tt.name, drive.Drive, expectedDrive)
+			}
+		})
+	}
+}
+
+func TestClient_ListDrives(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		listDrives func(ctx context.Context, client *Client) (*Drives, error)
+	}{
+		{"ListMyDrives", "/me/drives", func(ctx context.Context, client *Client) (*Drives, error) {
+			return client.ListMyDrives(ctx)
+		}},
+		{"ListUserDrives", "/users/fake_user_id/drives", func(ctx context.Context, client *Client) (*Drives, error) {
+			return client.ListUserDrives(ctx, "fake_user_id")
+		}},
+		{"ListSiteDrives", "/sites/fake_site_id/drives", func(ctx context.Context, client *Client) (*Drives, error) {
+			return client.ListSiteDrives(ctx, "fake_site_id")
+		}},
+		{"ListGroupDrives", "/groups/fake_group_id/drives", func(ctx context.Context, client *Client) (*Drives, error) {
+			return client.ListGroupDrives(ctx, "fake_group_id")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, teardown := setup_client()
+			defer teardown()
+
+			mux.HandleFunc(tt.path, func(w http.ResponseWriter, r *http.Request) {
+				testMethod(t, r, "GET")
+				jsonData := readFile(t, "fake_drives.json")
+				fmt.Fprint(w, string(jsonData))
+			})
+
+			drives, err := tt.listDrives(context.Background(), client)
+			if err != nil {
+				t.Fatalf("Client.%s returned error: %v", tt.name, err)
+			}
+			expectedDrives := getDataFromFile[*resources.Drives](t, "fake_drives.json")
+			if !reflect.DeepEqual(drives.raw, expectedDrives) {
+				t.Errorf("Client.%s returned %+v, want %+v", tt.name, drives.raw, expectedDrives)
+			}
+			if !drives.HasNext() {
+				t.Errorf("Client.%s returned drives without next link", tt.name)
+			}
+		})
+	}
+}
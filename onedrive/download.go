@@ -0,0 +1,223 @@
+package onedrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	http2 "net/http"
+	"os"
+
+	"github.com/bearcatat/onedrive-api/http"
+	"github.com/bearcatat/onedrive-api/quickxorhash"
+)
+
+// partialSuffix is appended to the path of a file while it is downloaded by
+// DownloadToFile. The eTag of the version being downloaded is kept next to
+// it, in a file with etagSuffix appended.
+const (
+	partialSuffix = ".partial"
+	etagSuffix    = ".etag"
+)
+
+// DownloadOptions configures a download. A nil *DownloadOptions downloads with
+// the defaults.
+type DownloadOptions struct {
+	// Progress is called as the bytes of the file are received.
+	Progress ProgressFunc
+	// RateLimiter limits the bandwidth of the download.
+	RateLimiter *RateLimiter
+	// Verify compares the QuickXorHash of the content received with the hash
+	// of the item, which must have been fetched with file.hashes. A mismatch
+	// returns a *HashMismatchError after the content was written. Ranges are
+	// not verified.
+	Verify bool
+	// ChunkSize is the size of the ranges DownloadParallel splits the
+	// content in. Defaults to 10 MiB.
+	ChunkSize int64
+	// Concurrency is the number of ranges DownloadParallel downloads at once.
+	// Defaults to 4.
+	Concurrency int
+}
+
+// downloadOptions returns the first of opts, or empty options.
+func downloadOptions(opts []*DownloadOptions) *DownloadOptions {
+	if len(opts) == 0 || opts[0] == nil {
+		return &DownloadOptions{}
+	}
+	return opts[0]
+}
+
+// DownloadRange writes length bytes of the content starting at offset to
+// writer. A negative length downloads up to the end of the content.
+func (i *DriveItem) DownloadRange(ctx context.Context, offset, length int64, writer io.Writer, opts ...*DownloadOptions) error {
+	if offset < 0 || length == 0 {
+		return ErrInvalidRange
+	}
+	options := downloadOptions(opts)
+	total := i.DriveItem.Size
+	if length > 0 {
+		total = offset + length
+	}
+	transfer := newTransfer(total, options.Progress, options.RateLimiter, i.downloadLimiter)
+	transfer.begin(offset)
+	return i.download(ctx, transfer.writer(ctx, writer, offset), offset, length)
+}
+
+// DownloadToFile downloads the content of the item to the file at path. The
+// content is written to path+".partial" and renamed to path once complete.
+// A partial file left by an interrupted call is continued if the item still
+// has the same eTag, and started over otherwise.
+func (i *DriveItem) DownloadToFile(ctx context.Context, path string, opts ...*DownloadOptions) error {
+	options := downloadOptions(opts)
+	item, err := newDrive(i.core, i.targetDrive()).Get(ctx, i.targetId())
+	if err != nil {
+		return err
+	}
+	if options.Verify && (item.File == nil || item.File.Hashes.QuickXorHash == "") {
+		return ErrHashUnavailable
+	}
+	partial := path + partialSuffix
+	offset, err := resumeOffset(partial, item)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(partial, os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if offset < item.Size {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		transfer := newTransfer(item.Size, options.Progress, options.RateLimiter, i.downloadLimiter)
+		transfer.begin(offset)
+		err = item.download(ctx, transfer.writer(ctx, file, offset), offset, -1)
+		if errors.Is(err, ErrItemChanged) {
+			file.Close()
+			removePartial(partial)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if options.Verify {
+		if err := verifyFile(partial, item); err != nil {
+			removePartial(partial)
+			return err
+		}
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return err
+	}
+	return os.Remove(partial + etagSuffix)
+}
+
+// resumeOffset returns where the download of item to partial continues and
+// records the eTag of item for the next attempt.
+func resumeOffset(partial string, item *DriveItem) (int64, error) {
+	etag, err := os.ReadFile(partial + etagSuffix)
+	if err == nil && item.ETag != "" && string(etag) == item.ETag {
+		if info, err := os.Stat(partial); err == nil && info.Size() <= item.Size {
+			return info.Size(), nil
+		}
+	}
+	if err := os.Remove(partial); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	return 0, os.WriteFile(partial+etagSuffix, []byte(item.ETag), 0666)
+}
+
+func removePartial(partial string) {
+	os.Remove(partial)
+	os.Remove(partial + etagSuffix)
+}
+
+// verifyFile compares the hash of the file at path with the hash of item.
+func verifyFile(path string, item *DriveItem) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	local := quickxorhash.New()
+	if _, err := io.Copy(local, file); err != nil {
+		return err
+	}
+	return verifyHash(local, item.File)
+}
+
+// download writes length bytes of the content from offset to writer, or the
+// whole content when offset is zero and length negative. An expired download
+// URL is resolved again once.
+func (i *DriveItem) download(ctx context.Context, writer io.Writer, offset, length int64) error {
+	err := i.downloadOnce(ctx, writer, offset, length)
+	if i.DriveItem.DownloadURL == "" || !isExpiredDownloadURL(err) {
+		return err
+	}
+	if err := i.refreshDownloadURL(ctx); err != nil {
+		return err
+	}
+	return i.downloadOnce(ctx, writer, offset, length)
+}
+
+// downloadOnce downloads from the download URL of the item, which is
+// pre-authenticated and gets no token, or from its content endpoint.
+func (i *DriveItem) downloadOnce(ctx context.Context, writer io.Writer, offset, length int64) error {
+	preAuthenticated := i.DriveItem.DownloadURL != ""
+	switch {
+	case offset == 0 && length < 0 && preAuthenticated:
+		return i.client.DownloadWithoutAuth(ctx, i.downloadRequest(), writer)
+	case offset == 0 && length < 0:
+		return i.client.Download(ctx, i.downloadRequest(), writer)
+	case preAuthenticated:
+		return i.client.DownloadRangeWithoutAuth(ctx, i.downloadRangeRequest(offset, length), writer)
+	}
+	return i.client.DownloadRange(ctx, i.downloadRangeRequest(offset, length), writer)
+}
+
+func (i *DriveItem) downloadRangeRequest(offset, length int64) http.Request {
+	return http.WithHeader(i.downloadRequest(), "Range", byteRange(offset, length))
+}
+
+// byteRange returns the Range header for length bytes from offset, or up to
+// the end when length is negative.
+func byteRange(offset, length int64) string {
+	if length < 0 {
+		return fmt.Sprintf("bytes=%d-", offset)
+	}
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// refreshDownloadURL fetches the item again for a new download URL. It fails
+// with ErrItemChanged when the eTag of the item changed.
+func (i *DriveItem) refreshDownloadURL(ctx context.Context) error {
+	fetched, err := newDrive(i.core, i.targetDrive()).Get(ctx, i.targetId())
+	if err != nil {
+		return err
+	}
+	// A shared item has the eTag of the reference, not of the remote item.
+	if i.DriveItem.RemoteItem == nil && fetched.ETag != i.DriveItem.ETag {
+		return ErrItemChanged
+	}
+	i.DriveItem.DownloadURL = fetched.DownloadURL
+	return nil
+}
+
+// isExpiredDownloadURL reports whether err is returned for a download URL
+// that is no longer valid.
+func isExpiredDownloadURL(err error) bool {
+	var graphError *GraphError
+	if !errors.As(err, &graphError) {
+		return false
+	}
+	switch graphError.StatusCode {
+	case http2.StatusUnauthorized, http2.StatusForbidden, http2.StatusGone:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearcatat/onedrive-api/auth"
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+// setup_download serves content at fake_download_url, with support for
+// ranges, and the metadata of the item with the eTag etag.
+func setup_download(t *testing.T, content string, etag string) (driveItem *DriveItem, mux *http.ServeMux, teardown func()) {
+	driveItem, mux, teardown = setup_drive_item()
+	downloadURL := driveItem.url.baseURL.String() + "fake_download_url"
+	driveItem.DownloadURL = downloadURL
+	driveItem.ETag = etag
+	driveItem.Size = int64(len(content))
+	mux.HandleFunc("/fake_download_url", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	})
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, `{"id": "fake_drive_item_id", "eTag": %q, "size": %d, "@microsoft.graph.downloadUrl": %q, "file": {"hashes": {"quickXorHash": %q}}}`,
+			etag, len(content), downloadURL, quickXorHashOf([]byte(content)))
+	})
+	return driveItem, mux, teardown
+}
+
+func TestDriveItem_DownloadRange(t *testing.T) {
+	driveItem, _, teardown := setup_download(t, "hello world!", "fake_etag")
+	defer teardown()
+
+	tests := []struct {
+		offset, length int64
+		want           string
+	}{
+		{6, 5, "world"},
+		{6, -1, "world!"},
+		{0, 5, "hello"},
+	}
+	for _, tt := range tests {
+		writer := &bytes.Buffer{}
+		err := driveItem.DownloadRange(context.Background(), tt.offset, tt.length, writer)
+		if err != nil || writer.String() != tt.want {
+			t.Errorf("DriveItem.DownloadRange(%d, %d) returned %q, %v, want %q", tt.offset, tt.length, writer.String(), err, tt.want)
+		}
+	}
+
+	err := driveItem.DownloadRange(context.Background(), -1, 5, &bytes.Buffer{})
+	if err != ErrInvalidRange {
+		t.Errorf("DriveItem.DownloadRange returned %v, want %v", err, ErrInvalidRange)
+	}
+}
+
+func TestDriveItem_Download_ExpiredDownloadURL(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusGone} {
+		driveItem, mux, teardown := setup_download(t, "hello world!", "fake_etag")
+		driveItem.DownloadURL = driveItem.url.baseURL.String() + "fake_expired_url"
+		mux.HandleFunc("/fake_expired_url", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+
+		writer := &bytes.Buffer{}
+		err := driveItem.DownloadRange(context.Background(), 6, -1, writer)
+		if err != nil || writer.String() != "world!" {
+			t.Errorf("DriveItem.DownloadRange after %d returned %q, %v, want %q", status, writer.String(), err, "world!")
+		}
+		if !strings.HasSuffix(driveItem.DownloadURL, "fake_download_url") {
+			t.Errorf("DriveItem.DownloadRange kept download URL %v", driveItem.DownloadURL)
+		}
+		teardown()
+	}
+}
+
+func TestDriveItem_Download_ItemChanged(t *testing.T) {
+	driveItem, mux, teardown := setup_download(t, "hello world!", "fake_new_etag")
+	defer teardown()
+	driveItem.ETag = "fake_etag"
+	driveItem.DownloadURL = driveItem.url.baseURL.String() + "fake_expired_url"
+	mux.HandleFunc("/fake_expired_url", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+
+	err := driveItem.DownloadRange(context.Background(), 6, -1, &bytes.Buffer{})
+	if err != ErrItemChanged {
+		t.Errorf("DriveItem.DownloadRange returned %v, want %v", err, ErrItemChanged)
+	}
+}
+
+func TestDriveItem_DownloadToFile(t *testing.T) {
+	content := "hello world!"
+	tests := []struct {
+		name    string
+		partial string
+		etag    string
+	}{
+		{"new", "", ""},
+		{"resumed", "hello ", "fake_etag"},
+		{"complete", "hello world!", "fake_etag"},
+		{"changed", "HELLO WORLD! AND MORE", "fake_old_etag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driveItem, _, teardown := setup_download(t, content, "fake_etag")
+			defer teardown()
+
+			path := filepath.Join(t.TempDir(), "fake_file.txt")
+			if tt.etag != "" {
+				os.WriteFile(path+partialSuffix, []byte(tt.partial), 0666)
+				os.WriteFile(path+partialSuffix+etagSuffix, []byte(tt.etag), 0666)
+			}
+
+			err := driveItem.DownloadToFile(context.Background(), path, &DownloadOptions{Verify: true})
+			if err != nil {
+				t.Fatalf("DriveItem.DownloadToFile returned error: %v", err)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != content {
+				t.Errorf("DriveItem.DownloadToFile wrote %q, want %q", got, content)
+			}
+			for _, leftover := range []string{path + partialSuffix, path + partialSuffix + etagSuffix} {
+				if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+					t.Errorf("DriveItem.DownloadToFile left %v", leftover)
+				}
+			}
+		})
+	}
+}
+
+func TestDriveItem_DownloadToFile_Resume(t *testing.T) {
+	content := "hello world!"
+	driveItem, mux, teardown := setup_download(t, content, "fake_etag")
+	defer teardown()
+	var ranges []string
+	mux.HandleFunc("/fake_ranged_download_url", func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	})
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_ranged_item_id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": "fake_ranged_item_id", "eTag": "fake_etag", "size": %d, "@microsoft.graph.downloadUrl": "%sfake_ranged_download_url"}`,
+			len(content), driveItem.url.baseURL.String())
+	})
+	driveItem.Id = "fake_ranged_item_id"
+
+	path := filepath.Join(t.TempDir(), "fake_file.txt")
+	os.WriteFile(path+partialSuffix, []byte("hello "), 0666)
+	os.WriteFile(path+partialSuffix+etagSuffix, []byte("fake_etag"), 0666)
+
+	err := driveItem.DownloadToFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("DriveItem.DownloadToFile returned error: %v", err)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=6-" {
+		t.Errorf("DriveItem.DownloadToFile requested ranges %q, want %q", ranges, []string{"bytes=6-"})
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != content {
+		t.Errorf("DriveItem.DownloadToFile wrote %q, want %q", got, content)
+	}
+}
+
+func TestDriveItem_Download_NoTokenForDownloadURL(t *testing.T) {
+	url, mux, teardown := setup()
+	defer teardown()
+	client := NewClientWithTokenSource(auth.StaticTokenSource(&auth.Token{AccessToken: "fake_access_token"}))
+	client.url.baseURL = url
+	driveItem := newDriveItem(client.core, &resources.DriveItem{Id: "fake_drive_item_id", Size: 12}, &resources.Drive{Id: "fake_drive_id"})
+	driveItem.DownloadURL = url.String() + "fake_download_url"
+
+	mux.HandleFunc("/fake_download_url", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "Authorization", "")
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader("hello world!"))
+	})
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id/content", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "Authorization", "Bearer fake_access_token")
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader("hello world!"))
+	})
+
+	ctx := context.Background()
+	writer := &bytes.Buffer{}
+	if err := driveItem.Download(ctx, writer); err != nil || writer.String() != "hello world!" {
+		t.Errorf("DriveItem.Download returned %q, %v", writer.String(), err)
+	}
+	writer.Reset()
+	if err := driveItem.DownloadRange(ctx, 6, 5, writer); err != nil || writer.String() != "world" {
+		t.Errorf("DriveItem.DownloadRange returned %q, %v", writer.String(), err)
+	}
+	parallel := &writerAtBuffer{}
+	if err := driveItem.DownloadParallel(ctx, parallel, &DownloadOptions{ChunkSize: 5}); err != nil || string(parallel.data) != "hello world!" {
+		t.Errorf("DriveItem.DownloadParallel returned %q, %v", parallel.data, err)
+	}
+
+	// Without a download URL the content endpoint is used with the token.
+	driveItem.DownloadURL = ""
+	writer.Reset()
+	if err := driveItem.DownloadRange(ctx, 0, 5, writer); err != nil || writer.String() != "hello" {
+		t.Errorf("DriveItem.DownloadRange returned %q, %v", writer.String(), err)
+	}
+}
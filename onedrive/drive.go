@@ -2,7 +2,11 @@ package onedrive
 
 import (
 	"context"
+	"fmt"
+	"io"
 	http2 "net/http"
+	"path"
+	"strings"
 
 	"github.com/bearcatat/onedrive-api/http"
 	"github.com/bearcatat/onedrive-api/resources"
@@ -12,6 +16,8 @@ type Drive struct {
 	*core
 
 	*resources.Drive
+
+	folders folderCache
 }
 
 func newDrive(c *core, drive *resources.Drive) *Drive {
@@ -21,30 +27,148 @@ func newDrive(c *core, drive *resources.Drive) *Drive {
 	}
 }
 
-func (d *Drive) GetByPath(ctx context.Context, path string) (*DriveItem, error) {
+// GetByPath returns the item at path. opts select the properties returned.
+func (d *Drive) GetByPath(ctx context.Context, path string, opts ...*QueryOptions) (*DriveItem, error) {
 	var driverItem *resources.DriveItem
-	err := d.client.DoWithAuth(ctx, d.getByPathRequest(path), &driverItem)
+	err := d.client.DoWithAuth(ctx, d.getByPathRequest(path, opts...), &driverItem)
 	if err != nil {
 		return nil, err
 	}
 	return newDriveItem(d.core, driverItem, d.Drive), nil
 }
 
-func (d *Drive) getByPathRequest(path string) http.Request {
-	url := d.url.GetDriveItemByPath(d.Drive.Id, path)
+func (d *Drive) getByPathRequest(path string, opts ...*QueryOptions) http.Request {
+	url := d.url.GetDriveItemByPath(d.Drive.Id, path, opts...)
 	return http.NewJsonRequest(http2.MethodGet, url, nil)
 }
 
-func (d *Drive) Get(ctx context.Context, itemId string) (*DriveItem, error) {
+// Get returns the item with id itemId. opts select the properties returned.
+func (d *Drive) Get(ctx context.Context, itemId string, opts ...*QueryOptions) (*DriveItem, error) {
 	var driverItem *resources.DriveItem
-	err := d.client.DoWithAuth(ctx, d.getRequest(itemId), &driverItem)
+	err := d.client.DoWithAuth(ctx, d.getRequest(itemId, opts...), &driverItem)
 	if err != nil {
 		return nil, err
 	}
 	return newDriveItem(d.core, driverItem, d.Drive), nil
 }
 
-func (d *Drive) getRequest(itemId string) http.Request {
-	url := d.url.Get(d.Drive.Id, itemId)
+func (d *Drive) getRequest(itemId string, opts ...*QueryOptions) http.Request {
+	url := d.url.Get(d.Drive.Id, itemId, opts...)
+	return http.NewJsonRequest(http2.MethodGet, url, nil)
+}
+
+// CreateFolderAt creates the folder at path. Its parent must exist.
+func (d *Drive) CreateFolderAt(ctx context.Context, path string) (*DriveItem, error) {
+	var driveItem *resources.DriveItem
+	err := d.client.DoWithAuth(ctx, d.createFolderAtRequest(path), &driveItem)
+	if err != nil {
+		return nil, err
+	}
+	return newDriveItem(d.core, driveItem, d.Drive), nil
+}
+
+func (d *Drive) createFolderAtRequest(folderPath string) http.Request {
+	parent, name := path.Split(strings.Trim(folderPath, "/"))
+	url := d.url.ListChildrenByPath(d.Drive.Id, parent)
+	return http.NewJsonRequest(http2.MethodPost, url, resources.NewCreateFolderRequest(name))
+}
+
+// UploadAt uploads size bytes read from r as the file at path, like
+// DriveItem.Upload. The parent folder of path must exist.
+func (d *Drive) UploadAt(ctx context.Context, filePath string, r io.Reader, size int64, opts ...*UploadOptions) (*DriveItem, error) {
+	parent, name := path.Split(strings.Trim(filePath, "/"))
+	return d.folderAt(parent).Upload(ctx, name, r, size, opts...)
+}
+
+// folderAt returns the folder at folderPath, addressed by its path.
+func (d *Drive) folderAt(folderPath string) *DriveItem {
+	folder := newDriveItem(d.core, &resources.DriveItem{}, d.Drive)
+	folder.path = "/" + strings.Trim(folderPath, "/")
+	return folder
+}
+
+// DeleteAt deletes the item at path.
+func (d *Drive) DeleteAt(ctx context.Context, path string) error {
+	d.folders.forget(path)
+	return d.client.DoWithAuth(ctx, d.deleteAtRequest(path), nil)
+}
+
+func (d *Drive) deleteAtRequest(path string) http.Request {
+	url := d.url.ItemByPath(d.Drive.Id, path)
+	return http.NewJsonRequest(http2.MethodDelete, url, nil)
+}
+
+// MoveAt moves the item at src into the folder dstDir and names it name. An
+// empty name keeps the name of the item.
+func (d *Drive) MoveAt(ctx context.Context, src, dstDir, name string) (*DriveItem, error) {
+	d.folders.forget(src)
+	var driveItem *resources.DriveItem
+	err := d.client.DoWithAuth(ctx, d.moveAtRequest(src, dstDir, name), &driveItem)
+	if err != nil {
+		return nil, err
+	}
+	return newDriveItem(d.core, driveItem, d.Drive), nil
+}
+
+func (d *Drive) moveAtRequest(src, dstDir, name string) http.Request {
+	url := d.url.ItemByPath(d.Drive.Id, src)
+	return http.NewJsonRequest(http2.MethodPatch, url, resources.NewMoveToPathRequest(d.folderReference(dstDir), name))
+}
+
+// CopyAt copies the item at src into the folder dstDir under the name name.
+// An empty name keeps the name of the item.
+func (d *Drive) CopyAt(ctx context.Context, src, dstDir, name string) (*AsyncJob, error) {
+	var asyncJob *resources.AsyncJob
+	err := d.client.DoWithAuth(ctx, d.copyAtRequest(src, dstDir, name), &asyncJob)
+	if err != nil {
+		return nil, err
+	}
+	return newAsyncJob(d.core, asyncJob, d.Drive), nil
+}
+
+func (d *Drive) copyAtRequest(src, dstDir, name string) http.Request {
+	url := d.url.CopyByPath(d.Drive.Id, src)
+	return http.NewJsonRequest(http2.MethodPost, url, resources.NewCopyToPathRequest(d.folderReference(dstDir), d.Drive, name))
+}
+
+// folderReference returns the parentReference path of the folder at dir.
+func (d *Drive) folderReference(dir string) string {
+	reference := fmt.Sprintf("/drives/%s/root:", d.Drive.Id)
+	if dir = strings.Trim(dir, "/"); dir != "" {
+		reference += "/" + dir
+	}
+	return reference
+}
+
+// ListChildrenAt lists the children of the folder at path. opts select,
+// filter and sort the children.
+func (d *Drive) ListChildrenAt(ctx context.Context, path string, opts ...*QueryOptions) (*Children, error) {
+	var children *resources.Children
+	err := d.client.DoWithAuth(ctx, d.listChildrenAtRequest(path, opts...), &children)
+	if err != nil {
+		return nil, err
+	}
+	return newChildren(d.core, children, d.Drive), nil
+}
+
+func (d *Drive) listChildrenAtRequest(path string, opts ...*QueryOptions) http.Request {
+	url := d.url.ListChildrenByPath(d.Drive.Id, path, opts...)
+	return http.NewJsonRequest(http2.MethodGet, url, nil)
+}
+
+// DownloadAt writes the content of the file at path to writer. Progress is
+// reported without a total, and Verify is not supported since the hash of
+// the file is not fetched.
+func (d *Drive) DownloadAt(ctx context.Context, path string, writer io.Writer, opts ...*DownloadOptions) error {
+	options := downloadOptions(opts)
+	if options.Verify {
+		return ErrHashUnavailable
+	}
+	transfer := newTransfer(-1, options.Progress, options.RateLimiter, d.downloadLimiter)
+	return d.client.Download(ctx, d.downloadAtRequest(path), transfer.writer(ctx, writer, 0))
+}
+
+func (d *Drive) downloadAtRequest(path string) http.Request {
+	url := d.url.ContentByPath(d.Drive.Id, path)
 	return http.NewJsonRequest(http2.MethodGet, url, nil)
 }
@@ -2,11 +2,14 @@ package onedrive
 
 import (
 	"context"
+	"errors"
 	"io"
 	http2 "net/http"
 	"net/url"
+	"path"
 
 	"github.com/bearcatat/onedrive-api/http"
+	"github.com/bearcatat/onedrive-api/quickxorhash"
 	"github.com/bearcatat/onedrive-api/resources"
 )
 
@@ -15,6 +18,9 @@ type DriveItem struct {
 	*resources.DriveItem
 
 	drive *resources.Drive
+	// path addresses a folder by its path from the root of the drive, for
+	// uploads to a folder whose id is not known.
+	path string
 }
 
 func newDriveItem(c *core, driveItem *resources.DriveItem, drive *resources.Drive) *DriveItem {
@@ -25,90 +31,196 @@ func newDriveItem(c *core, driveItem *resources.DriveItem, drive *resources.Driv
 	}
 }
 
+// targetDrive returns the drive holding the content of the item. Items shared
+// from another drive live in the drive of their remoteItem.
+func (i *DriveItem) targetDrive() *resources.Drive {
+	remote := i.DriveItem.RemoteItem
+	if remote == nil || remote.ParentReference == nil || remote.ParentReference.DriveID == "" {
+		return i.drive
+	}
+	if remote.ParentReference.DriveID == i.drive.Id {
+		return i.drive
+	}
+	return &resources.Drive{
+		Id:        remote.ParentReference.DriveID,
+		DriveType: remote.ParentReference.DriveType,
+	}
+}
+
+// targetId returns the id of the item in targetDrive.
+func (i *DriveItem) targetId() string {
+	if remote := i.DriveItem.RemoteItem; remote != nil && remote.Id != "" {
+		return remote.Id
+	}
+	return i.DriveItem.Id
+}
+
 func (i *DriveItem) CreateFolder(ctx context.Context, folderName string) (*DriveItem, error) {
 	var driveItem *resources.DriveItem
 	err := i.client.DoWithAuth(ctx, i.createFolderRequest(folderName), &driveItem)
 	if err != nil {
 		return nil, err
 	}
-	return newDriveItem(i.core, driveItem, i.drive), nil
+	return newDriveItem(i.core, driveItem, i.targetDrive()), nil
 }
 
 func (i *DriveItem) createFolderRequest(folderName string) http.Request {
-	url := i.url.CreateFolder(i.drive.Id, i.DriveItem.Id)
+	url := i.url.CreateFolder(i.targetDrive().Id, i.targetId())
 	body := resources.NewCreateFolderRequest(folderName)
 	return http.NewJsonRequest(http2.MethodPost, url, body)
 }
 
-func (i *DriveItem) UploadLargeFile(ctx context.Context, file File) (*DriveItem, error) {
-	if file.IsDir() {
-		return nil, ErrNotFile
+// Upload uploads size bytes read from r as a file named name in the folder.
+// Files smaller than 4 MiB, including empty files, are sent in a single
+// request; larger files, and non-empty files uploaded with options only an
+// upload session supports, are uploaded through an upload session. The
+// description and timestamps of an empty file are set by a second request.
+// A negative size returns ErrInvalidSize, and an empty file with DeferCommit
+// returns ErrEmptyFile.
+func (i *DriveItem) Upload(ctx context.Context, name string, r io.Reader, size int64, opts ...*UploadOptions) (*DriveItem, error) {
+	options, err := uploadOptions(opts)
+	if err != nil {
+		return nil, err
 	}
-	if file.Size() == 0 {
+	if size < 0 {
+		return nil, ErrInvalidSize
+	}
+	if size == 0 && options.DeferCommit {
 		return nil, ErrEmptyFile
 	}
-	uploadSession, err := i.createUploadSession(ctx, file)
+	if size >= simpleUploadLimit || (size > 0 && options.needsSession()) {
+		return i.UploadLargeFile(ctx, newReaderFile(name, r, size), options)
+	}
+	content := make([]byte, size)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	transfer := options.transfer(i.core, size)
+	req := http.WithBodyReader(i.uploadContentRequest(name, content, options), func(r io.Reader) io.Reader {
+		return transfer.reader(ctx, r, 0, 0)
+	})
+	var driveItem *resources.DriveItem
+	err = i.client.DoWithAuth(ctx, req, &driveItem)
 	if err != nil {
 		return nil, err
 	}
-	return i.uploadFileToUploadSession(ctx, file, uploadSession)
+	item := newDriveItem(i.core, driveItem, i.targetDrive())
+	if options.hasMetadata() {
+		item, err = item.Update(ctx, &DriveItem{DriveItem: options.metadata()})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if options.Verify {
+		local := quickxorhash.New()
+		local.Write(content)
+		return item, i.verifyUpload(ctx, item, local)
+	}
+	return item, nil
 }
 
-func (i *DriveItem) createUploadSession(ctx context.Context, file File) (*resources.UploadSession, error) {
-	var uploadSession *resources.UploadSession
-	err := i.client.DoWithAuth(ctx, i.createUploadSessionRequest(file), &uploadSession)
-	if err != nil {
-		return nil, err
+func (i *DriveItem) uploadContentRequest(name string, content []byte, options *UploadOptions) http.Request {
+	url := i.url.UploadContent(i.targetDrive().Id, i.targetId(), name)
+	if i.path != "" {
+		url = i.url.ContentByPath(i.drive.Id, path.Join(i.path, name))
 	}
-	return uploadSession, nil
+	query := url.Query()
+	query.Set("@microsoft.graph.conflictBehavior", string(options.conflictBehavior()))
+	url.RawQuery = query.Encode()
+	req := http.NewContentRequest(http2.MethodPut, url, content)
+	if options.IfMatch != "" {
+		req = http.WithHeader(req, "If-Match", options.IfMatch)
+	}
+	return req
 }
 
-func (i *DriveItem) createUploadSessionRequest(file File) http.Request {
-	url := i.url.UploadSession(i.drive.Id, i.DriveItem.Id, file.Name())
-	body := resources.NewUploadSessionRequest()
-	return http.NewJsonRequest(http2.MethodPost, url, body)
+func (i *DriveItem) UploadLargeFile(ctx context.Context, file File, opts ...*UploadOptions) (*DriveItem, error) {
+	options, err := uploadOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if file.IsDir() {
+		return nil, ErrNotFile
+	}
+	if file.Size() == 0 {
+		return nil, ErrEmptyFile
+	}
+	return i.uploadWithSession(ctx, file.Name(), file.Size(), newFileForUpload(file), options)
 }
 
-func (i *DriveItem) uploadFileToUploadSession(ctx context.Context, file File, session *resources.UploadSession) (*DriveItem, error) {
-	fileForUpload := newFileForUpload(file, session)
-	var response *resources.UploadSessionResponse
-	for fileForUpload.next() {
-		var err error
-		response, err = i.uploadFileFragmentToUploadSession(ctx, fileForUpload)
+// uploadWithSession uploads src through an upload session. When the session
+// expires the upload starts over in a new session if src can be rewound.
+func (i *DriveItem) uploadWithSession(ctx context.Context, name string, size int64, src fragmentSource, options *UploadOptions) (*DriveItem, error) {
+	transfer := options.transfer(i.core, size)
+	var hashing *hashingSource
+	if options.Verify {
+		hashing = newHashingSource(src)
+		src = hashing
+	}
+	for restarts := 0; ; restarts++ {
+		session, err := i.CreateUploadSession(ctx, name, size, options)
 		if err != nil {
 			return nil, err
 		}
+		if options.OnSession != nil {
+			options.OnSession(session)
+		}
+		item, err := session.upload(ctx, src, 0, transfer)
+		if err == nil {
+			item = newDriveItem(i.core, item.DriveItem, i.targetDrive())
+			if hashing != nil {
+				return item, i.verifySource(ctx, item, hashing, size)
+			}
+			return item, nil
+		}
+		if !errors.Is(err, ErrUploadSessionExpired) || restarts >= maxSessionRestarts {
+			return nil, err
+		}
+		if src.rewind() != nil {
+			return nil, err
+		}
 	}
-	return newDriveItem(i.core, &response.DriveItem, i.drive), nil
 }
 
-func (i *DriveItem) uploadFileFragmentToUploadSession(ctx context.Context, file *fileForUpload) (*resources.UploadSessionResponse, error) {
-	var response *resources.UploadSessionResponse
-	req, err := file.getNextRequest()
+func (i *DriveItem) createUploadSession(ctx context.Context, name string, options *UploadOptions) (*resources.UploadSession, error) {
+	var uploadSession *resources.UploadSession
+	err := i.client.DoWithAuth(ctx, i.createUploadSessionRequest(name, options), &uploadSession)
 	if err != nil {
 		return nil, err
 	}
-	err = i.client.DoWithoutAuth(ctx, req, &response)
-	if err != nil {
-		return nil, err
+	return uploadSession, nil
+}
+
+func (i *DriveItem) createUploadSessionRequest(name string, options *UploadOptions) http.Request {
+	url := i.url.UploadSession(i.targetDrive().Id, i.targetId(), name)
+	if i.path != "" {
+		url = i.url.UploadSessionByPath(i.drive.Id, path.Join(i.path, name))
+	}
+	req := http.NewJsonRequest(http2.MethodPost, url, options.uploadSessionRequest())
+	if options.IfMatch != "" {
+		req = http.WithHeader(req, "If-Match", options.IfMatch)
 	}
-	return response, nil
+	return req
 }
 
+// Update updates the item. For an item shared from another drive the remote
+// item is updated.
 func (i *DriveItem) Update(ctx context.Context, update *DriveItem) (*DriveItem, error) {
 	var driveItem *resources.DriveItem
 	err := i.client.DoWithAuth(ctx, i.updateRequest(update), &driveItem)
 	if err != nil {
 		return nil, err
 	}
-	return newDriveItem(i.core, driveItem, i.drive), nil
+	return newDriveItem(i.core, driveItem, i.targetDrive()), nil
 }
 
 func (i *DriveItem) updateRequest(item *DriveItem) http.Request {
-	url := i.url.Update(i.drive.Id, i.DriveItem.Id)
+	url := i.url.Update(i.targetDrive().Id, i.targetId())
 	return http.NewJsonRequest(http2.MethodPatch, url, item)
 }
 
+// Delete deletes the item. For an item shared from another drive only the
+// reference in this drive is deleted, not the remote item.
 func (i *DriveItem) Delete(ctx context.Context) error {
 	return i.client.DoWithAuth(ctx, i.deleteReqeust(), nil)
 }
@@ -124,44 +236,92 @@ func (i *DriveItem) Copy(ctx context.Context, parentItem *DriveItem, newName str
 	if err != nil {
 		return nil, err
 	}
-	return newAsyncJob(i.core, asyncJob, i.drive), nil
+	return newAsyncJob(i.core, asyncJob, parentItem.targetDrive()), nil
 }
 
 func (i *DriveItem) copyRequest(parentItem *DriveItem, newName string) http.Request {
-	url := i.url.Copy(i.drive.Id, i.DriveItem.Id)
-	return http.NewJsonRequest(http2.MethodPost, url, resources.NewCopyRequest(parentItem.DriveItem, parentItem.drive, newName))
+	url := i.url.Copy(i.targetDrive().Id, i.targetId())
+	parent := &resources.DriveItem{Id: parentItem.targetId()}
+	return http.NewJsonRequest(http2.MethodPost, url, resources.NewCopyRequest(parent, parentItem.targetDrive(), newName))
 }
 
+// Move moves the item into parentItem, renaming it to newName unless newName
+// is empty. For an item shared from another drive the remote item is moved,
+// so parentItem must be in the drive of the remote item.
 func (i *DriveItem) Move(ctx context.Context, parentItem *DriveItem, newName string) (*DriveItem, error) {
 	var item *resources.DriveItem
 	err := i.client.DoWithAuth(ctx, i.moveRequest(parentItem, newName), &item)
 	if err != nil {
 		return nil, err
 	}
-	return newDriveItem(i.core, item, i.drive), nil
+	return newDriveItem(i.core, item, i.targetDrive()), nil
 }
 
 func (i *DriveItem) moveRequest(parentItem *DriveItem, newName string) http.Request {
-	url := i.url.Move(i.drive.Id, i.DriveItem.Id)
-	return http.NewJsonRequest(http2.MethodPatch, url, resources.NewMoveRequest(parentItem.DriveItem, parentItem.drive, newName))
+	url := i.url.Move(i.targetDrive().Id, i.targetId())
+	parent := &resources.DriveItem{Id: parentItem.targetId()}
+	return http.NewJsonRequest(http2.MethodPatch, url, resources.NewMoveRequest(parent, parentItem.targetDrive(), newName))
+}
+
+// moveReplacing moves the item like Move, replacing an item with the same
+// name in parentItem in the same request.
+func (i *DriveItem) moveReplacing(ctx context.Context, parentItem *DriveItem, newName string) (*DriveItem, error) {
+	var item *resources.DriveItem
+	err := i.client.DoWithAuth(ctx, i.moveReplacingRequest(parentItem, newName), &item)
+	if err != nil {
+		return nil, err
+	}
+	return newDriveItem(i.core, item, i.targetDrive()), nil
 }
 
-func (i *DriveItem) ListChildren(ctx context.Context) (*Children, error) {
+func (i *DriveItem) moveReplacingRequest(parentItem *DriveItem, newName string) http.Request {
+	url := i.url.Move(i.targetDrive().Id, i.targetId())
+	query := url.Query()
+	query.Set("@microsoft.graph.conflictBehavior", string(resources.ConflictBehaviorReplace))
+	url.RawQuery = query.Encode()
+	parent := &resources.DriveItem{Id: parentItem.targetId()}
+	return http.NewJsonRequest(http2.MethodPatch, url, resources.NewMoveRequest(parent, parentItem.targetDrive(), newName))
+}
+
+// ListChildren lists the first page of the children of the folder. opts
+// select, filter and sort the children.
+func (i *DriveItem) ListChildren(ctx context.Context, opts ...*QueryOptions) (*Children, error) {
+	options := &ChildrenOptions{}
+	if len(opts) > 0 {
+		options.Query = opts[0]
+	}
+	return i.listChildren(ctx, options)
+}
+
+func (i *DriveItem) listChildren(ctx context.Context, options *ChildrenOptions) (*Children, error) {
 	var children *resources.Children
-	err := i.client.DoWithAuth(ctx, i.listChildrenRequest(), &children)
+	err := i.client.DoWithAuth(ctx, i.listChildrenRequest(options), &children)
 	if err != nil {
 		return nil, err
 	}
-	return newChildren(i.core, children, i.drive), nil
+	return newChildren(i.core, children, i.targetDrive()), nil
 }
 
-func (i *DriveItem) listChildrenRequest() http.Request {
-	url := i.url.ListChildren(i.drive.Id, i.DriveItem.Id)
+func (i *DriveItem) listChildrenRequest(options *ChildrenOptions) http.Request {
+	url := i.url.ListChildren(i.targetDrive().Id, i.targetId(), options.query())
 	return http.NewJsonRequest(http2.MethodGet, url, nil)
 }
 
-func (i *DriveItem) Download(ctx context.Context, writer io.Writer) error {
-	return i.client.Download(ctx, i.downloadRequest(), writer)
+func (i *DriveItem) Download(ctx context.Context, writer io.Writer, opts ...*DownloadOptions) error {
+	options := downloadOptions(opts)
+	if options.Verify && (i.File == nil || i.File.Hashes.QuickXorHash == "") {
+		return ErrHashUnavailable
+	}
+	transfer := newTransfer(i.DriveItem.Size, options.Progress, options.RateLimiter, i.downloadLimiter)
+	local := quickxorhash.New()
+	if options.Verify {
+		writer = io.MultiWriter(writer, local)
+	}
+	err := i.download(ctx, transfer.writer(ctx, writer, 0), 0, -1)
+	if err != nil || !options.Verify {
+		return err
+	}
+	return verifyHash(local, i.File)
 }
 
 func (i *DriveItem) downloadRequest() http.Request {
@@ -169,7 +329,7 @@ func (i *DriveItem) downloadRequest() http.Request {
 	if i.DriveItem.DownloadURL != "" {
 		downloadURL, _ = url.Parse(i.DriveItem.DownloadURL)
 	} else {
-		downloadURL = i.url.Download(i.drive.Id, i.DriveItem.Id)
+		downloadURL = i.url.Download(i.targetDrive().Id, i.targetId())
 	}
 	return http.NewJsonRequest(http2.MethodGet, downloadURL, nil)
 }
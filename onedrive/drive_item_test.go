@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bearcatat/onedrive-api/resources"
 )
@@ -60,7 +63,7 @@ func TestDriveItem_createUploadSession(t *testing.T) {
 	})
 
 	ctx := context.Background()
-	uploadSession, err := driveItem.createUploadSession(ctx, &fakeFile{})
+	uploadSession, err := driveItem.createUploadSession(ctx, "fake_file_name", &UploadOptions{})
 	if err != nil {
 		t.Errorf("DriveItem.createUploadSession returned error: %v", err)
 	}
@@ -94,9 +97,10 @@ func TestDriveItem_UploadLargeFile(t *testing.T) {
 			jsonData := readFile(t, "fake_drive_item.json")
 			fmt.Fprint(w, string(jsonData))
 		} else {
+			var start, end, total int64
+			fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total)
 			w.WriteHeader(http.StatusAccepted)
-			jsonData := readFile(t, "fake_upload_session_response.json")
-			fmt.Fprint(w, string(jsonData))
+			fmt.Fprintf(w, `{"nextExpectedRanges": ["%d-"]}`, end+1)
 		}
 	})
 
@@ -337,3 +341,276 @@ func TestDriveItem_Download_UseDownloadURL(t *testing.T) {
 		t.Errorf("DriveItem.Download returned %+v, want %+v", writer.Bytes(), expected)
 	}
 }
+
+func setup_remote_drive_item() (driveItem *DriveItem, mux *http.ServeMux, teardown func()) {
+	driveItem, mux, teardown = setup_drive_item()
+	driveItem.RemoteItem = &resources.RemoteItem{
+		Id: "fake_remote_item_id",
+		ParentReference: &resources.ItemReference{
+			DriveID:   "fake_remote_drive_id",
+			DriveType: string(resources.DriveTypeBusiness),
+		},
+	}
+	return driveItem, mux, teardown
+}
+
+func TestDriveItem_ListChildren_RemoteItem(t *testing.T) {
+	driveItem, mux, teardown := setup_remote_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_remote_drive_id/items/fake_remote_item_id/children", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		jsonData := readFile(t, "fake_children.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	children, err := driveItem.ListChildren(context.Background())
+	if err != nil {
+		t.Fatalf("DriveItem.ListChildren returned error: %v", err)
+	}
+	for _, child := range children.Value {
+		if child.drive.Id != "fake_remote_drive_id" {
+			t.Errorf("DriveItem.ListChildren returned child in drive %q, want fake_remote_drive_id", child.drive.Id)
+		}
+	}
+}
+
+func TestDriveItem_CreateFolder_RemoteItem(t *testing.T) {
+	driveItem, mux, teardown := setup_remote_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_remote_drive_id/items/fake_remote_item_id/children", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		jsonData := readFile(t, "fake_drive_item.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	item, err := driveItem.CreateFolder(context.Background(), "test_folder")
+	if err != nil {
+		t.Fatalf("DriveItem.CreateFolder returned error: %v", err)
+	}
+	if item.drive.Id != "fake_remote_drive_id" {
+		t.Errorf("DriveItem.CreateFolder returned item in drive %q, want fake_remote_drive_id", item.drive.Id)
+	}
+}
+
+func TestDriveItem_Download_RemoteItem(t *testing.T) {
+	driveItem, mux, teardown := setup_remote_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_remote_drive_id/items/fake_remote_item_id/content", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write(readFile(t, "fake_file.txt"))
+	})
+
+	writer := &bytes.Buffer{}
+	err := driveItem.Download(context.Background(), writer)
+	if err != nil {
+		t.Errorf("DriveItem.Download returned error: %v", err)
+	}
+	if !bytes.Equal(writer.Bytes(), readFile(t, "fake_file.txt")) {
+		t.Errorf("DriveItem.Download returned %+v", writer.Bytes())
+	}
+}
+
+func TestDriveItem_Delete_RemoteItem(t *testing.T) {
+	driveItem, mux, teardown := setup_remote_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	err := driveItem.Delete(context.Background())
+	if err != nil {
+		t.Errorf("DriveItem.Delete returned error: %v", err)
+	}
+}
+
+func TestDriveItem_Update_RemoteItem(t *testing.T) {
+	driveItem, mux, teardown := setup_remote_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_remote_drive_id/items/fake_remote_item_id", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		jsonData := readFile(t, "fake_drive_item.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	item, err := driveItem.Update(context.Background(), &DriveItem{})
+	if err != nil {
+		t.Fatalf("DriveItem.Update returned error: %v", err)
+	}
+	if item.drive.Id != "fake_remote_drive_id" {
+		t.Errorf("DriveItem.Update returned item in drive %q, want fake_remote_drive_id", item.drive.Id)
+	}
+}
+
+func TestDriveItem_Move_RemoteItem(t *testing.T) {
+	driveItem, mux, teardown := setup_remote_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_remote_drive_id/items/fake_remote_item_id", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		expectedRequestBody := getDataFromFile[*resources.DriveItem](t, "fake_move_request_body.json")
+		testBody(t, r, expectedRequestBody)
+
+		jsonData := readFile(t, "fake_drive_item.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	item, err := driveItem.Move(context.Background(), &DriveItem{
+		DriveItem: &resources.DriveItem{
+			Id: "fake_parent_drive_item_id",
+		},
+		drive: driveItem.targetDrive(),
+	}, "fake_name")
+	if err != nil {
+		t.Fatalf("DriveItem.Move returned error: %v", err)
+	}
+	if item.drive.Id != "fake_remote_drive_id" {
+		t.Errorf("DriveItem.Move returned item in drive %q, want fake_remote_drive_id", item.drive.Id)
+	}
+}
+
+func TestDriveItem_Upload(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	content := readFile(t, "fake_file.txt")
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/content", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		testHeader(t, r, "Content-Type", "application/octet-stream")
+		body, _ := io.ReadAll(r.Body)
+		if !bytes.Equal(body, content) {
+			t.Errorf("Request body: %q, want %q", body, content)
+		}
+		w.WriteHeader(http.StatusCreated)
+		jsonData := readFile(t, "fake_drive_item.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	item, err := driveItem.Upload(context.Background(), "fake_file_name", bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Errorf("DriveItem.Upload returned error: %v", err)
+	}
+	expectedItem := getDataFromFile[*resources.DriveItem](t, "fake_drive_item.json")
+	if !reflect.DeepEqual(item.DriveItem, expectedItem) {
+		t.Errorf("DriveItem.Upload returned %+v, want %+v", item.DriveItem, expectedItem)
+	}
+}
+
+func TestDriveItem_Upload_EscapedName(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/", func(w http.ResponseWriter, r *http.Request) {
+		want := "/drives/fake_drive_id/items/fake_drive_item_id:/50%25%20off%20%231%3F.txt:/content"
+		if r.URL.EscapedPath() != want {
+			t.Errorf("Request path: %v, want %v", r.URL.EscapedPath(), want)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, string(readFile(t, "fake_drive_item.json")))
+	})
+
+	_, err := driveItem.Upload(context.Background(), "50% off #1?.txt", strings.NewReader("hello"), 5)
+	if err != nil {
+		t.Errorf("DriveItem.Upload returned error: %v", err)
+	}
+}
+
+func TestDriveItem_Upload_EmptyFile(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_empty_file_name:/content", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		testHeader(t, r, "Content-Length", "0")
+		w.WriteHeader(http.StatusCreated)
+		jsonData := readFile(t, "fake_drive_item.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	_, err := driveItem.Upload(context.Background(), "fake_empty_file_name", &fakeEmptyFile{}, 0)
+	if err != nil {
+		t.Errorf("DriveItem.Upload returned error: %v", err)
+	}
+}
+
+func TestDriveItem_Upload_EmptyFileMetadata(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_empty_file_name:/content", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		testHeader(t, r, "Content-Length", "0")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, string(readFile(t, "fake_drive_item.json")))
+	})
+	updated := false
+	mux.HandleFunc("/drives/fake_drive_id/items/XXXXXXXXXXXX!105", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		testBody(t, r, &resources.DriveItem{
+			Description:    "fake description",
+			FileSystemInfo: &resources.FileSystemInfo{LastModifiedDateTime: modified},
+		})
+		updated = true
+		fmt.Fprint(w, string(readFile(t, "fake_drive_item.json")))
+	})
+
+	_, err := driveItem.Upload(context.Background(), "fake_empty_file_name", &fakeEmptyFile{}, 0, &UploadOptions{
+		Description:          "fake description",
+		LastModifiedDateTime: modified,
+	})
+	if err != nil {
+		t.Errorf("DriveItem.Upload returned error: %v", err)
+	}
+	if !updated {
+		t.Errorf("DriveItem.Upload did not set the description and timestamps of the empty file")
+	}
+}
+
+func TestDriveItem_Upload_EmptyFileDeferCommit(t *testing.T) {
+	driveItem, _, teardown := setup_drive_item()
+	defer teardown()
+
+	_, err := driveItem.Upload(context.Background(), "fake_empty_file_name", &fakeEmptyFile{}, 0, &UploadOptions{DeferCommit: true})
+	if err != ErrEmptyFile {
+		t.Errorf("DriveItem.Upload returned %v, want %v", err, ErrEmptyFile)
+	}
+}
+
+func TestDriveItem_Upload_NegativeSize(t *testing.T) {
+	driveItem, _, teardown := setup_drive_item()
+	defer teardown()
+
+	_, err := driveItem.Upload(context.Background(), "fake_file_name", strings.NewReader("hello"), -1)
+	if err != ErrInvalidSize {
+		t.Errorf("DriveItem.Upload returned %v, want %v", err, ErrInvalidSize)
+	}
+}
+
+func TestDriveItem_Upload_LargeFile(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	size := int64(simpleUploadLimit)
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	mux.HandleFunc("/fake_upload_url", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		testHeader(t, r, "Content-Range", fmt.Sprintf("bytes 0-%d/%d", size-1, size))
+		w.WriteHeader(http.StatusCreated)
+		jsonData := readFile(t, "fake_drive_item.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	_, err := driveItem.Upload(context.Background(), "fake_file_name", bytes.NewReader(make([]byte, size)), size)
+	if err != nil {
+		t.Errorf("DriveItem.Upload returned error: %v", err)
+	}
+}
@@ -1,10 +1,14 @@
 package onedrive
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/bearcatat/onedrive-api/resources"
@@ -59,3 +63,159 @@ func TestDrive_Get(t *testing.T) {
 		t.Errorf("Drive.Get returned %+v, want %+v", item.DriveItem, expectedItem)
 	}
 }
+
+func TestDrive_Get_NotFound(t *testing.T) {
+	drive, mux, teardown := setup_drive()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_item_id", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.WriteHeader(http.StatusNotFound)
+		jsonData := readFile(t, "fake_error.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	ctx := context.Background()
+	_, err := drive.Get(ctx, "fake_item_id")
+	if !IsNotFound(err) {
+		t.Errorf("Drive.Get returned %v, want not found error", err)
+	}
+	var graphError *GraphError
+	if !errors.As(err, &graphError) {
+		t.Fatalf("Drive.Get returned %v, want *GraphError", err)
+	}
+	if graphError.Code != "itemNotFound" || graphError.RequestId != "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX" {
+		t.Errorf("Drive.Get returned %+v", graphError)
+	}
+}
+
+// pathRequest is a request recorded by setup_path_drive.
+type pathRequest struct {
+	method string
+	path   string
+	body   string
+}
+
+// setup_path_drive records the requests to the drive, answering them with
+// the fake drive item, and serves "hello" as the content of files.
+func setup_path_drive(t *testing.T) (drive *Drive, mux *http.ServeMux, requests *[]pathRequest, teardown func()) {
+	drive, mux, teardown = setup_drive()
+	requests = &[]pathRequest{}
+	mux.HandleFunc("/drives/fake_drive_id/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*requests = append(*requests, pathRequest{r.Method, r.URL.EscapedPath(), strings.TrimSpace(string(body))})
+		switch {
+		case strings.HasSuffix(r.URL.Path, ":/createUploadSession"):
+			fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, drive.url.baseURL.String())
+		case strings.HasSuffix(r.URL.Path, "/copy"):
+			w.Header().Set("Location", drive.url.baseURL.String()+"async_job")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/content"):
+			fmt.Fprint(w, "hello")
+		case strings.HasSuffix(r.URL.Path, "/children") && r.Method == http.MethodGet:
+			fmt.Fprint(w, string(readFile(t, "fake_children.json")))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			fmt.Fprint(w, string(readFile(t, "fake_drive_item.json")))
+		}
+	})
+	return drive, mux, requests, teardown
+}
+
+func TestDrive_PathOperations(t *testing.T) {
+	drive, _, requests, teardown := setup_path_drive(t)
+	defer teardown()
+
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		call func() error
+		want pathRequest
+	}{
+		{
+			"CreateFolderAt",
+			func() error { _, err := drive.CreateFolderAt(ctx, "My Docs/New #1"); return err },
+			pathRequest{"POST", "/drives/fake_drive_id/root:/My%20Docs:/children", `{"name":"New #1","folder":{},"@microsoft.graph.conflictBehavior":"rename"}`},
+		},
+		{
+			"CreateFolderAt in the root",
+			func() error { _, err := drive.CreateFolderAt(ctx, "/Top/"); return err },
+			pathRequest{"POST", "/drives/fake_drive_id/root/children", `{"name":"Top","folder":{},"@microsoft.graph.conflictBehavior":"rename"}`},
+		},
+		{
+			"UploadAt",
+			func() error {
+				_, err := drive.UploadAt(ctx, "My Docs/a#b%.txt", strings.NewReader("hello"), 5)
+				return err
+			},
+			pathRequest{"PUT", "/drives/fake_drive_id/root:/My%20Docs/a%23b%25.txt:/content", "hello"},
+		},
+		{
+			"UploadAt in the root",
+			func() error { _, err := drive.UploadAt(ctx, "a.txt", strings.NewReader("hello"), 5); return err },
+			pathRequest{"PUT", "/drives/fake_drive_id/root:/a.txt:/content", "hello"},
+		},
+		{
+			"DeleteAt",
+			func() error { return drive.DeleteAt(ctx, "My Docs/a#b%.txt") },
+			pathRequest{"DELETE", "/drives/fake_drive_id/root:/My%20Docs/a%23b%25.txt:", ""},
+		},
+		{
+			"MoveAt",
+			func() error { _, err := drive.MoveAt(ctx, "My Docs/a#b%.txt", "Archive/2024", "b.txt"); return err },
+			pathRequest{"PATCH", "/drives/fake_drive_id/root:/My%20Docs/a%23b%25.txt:", `{"name":"b.txt","parentReference":{"path":"/drives/fake_drive_id/root:/Archive/2024"}}`},
+		},
+		{
+			"CopyAt",
+			func() error { _, err := drive.CopyAt(ctx, "My Docs/a#b%.txt", "", ""); return err },
+			pathRequest{"POST", "/drives/fake_drive_id/root:/My%20Docs/a%23b%25.txt:/copy", `{"parentReference":{"driveId":"fake_drive_id","path":"/drives/fake_drive_id/root:"}}`},
+		},
+		{
+			"ListChildrenAt",
+			func() error { _, err := drive.ListChildrenAt(ctx, "My Docs"); return err },
+			pathRequest{"GET", "/drives/fake_drive_id/root:/My%20Docs:/children", ""},
+		},
+		{
+			"DownloadAt",
+			func() error {
+				writer := &bytes.Buffer{}
+				err := drive.DownloadAt(ctx, "My Docs/a#b%.txt", writer)
+				if err == nil && writer.String() != "hello" {
+					t.Errorf("Drive.DownloadAt wrote %q, want %q", writer.String(), "hello")
+				}
+				return err
+			},
+			pathRequest{"GET", "/drives/fake_drive_id/root:/My%20Docs/a%23b%25.txt:/content", ""},
+		},
+	}
+	for _, tt := range tests {
+		*requests = nil
+		if err := tt.call(); err != nil {
+			t.Errorf("Drive.%s returned error: %v", tt.name, err)
+		}
+		if len(*requests) != 1 || (*requests)[0] != tt.want {
+			t.Errorf("Drive.%s sent %+v, want %+v", tt.name, *requests, tt.want)
+		}
+	}
+}
+
+func TestDrive_UploadAt_LargeFile(t *testing.T) {
+	drive, mux, requests, teardown := setup_path_drive(t)
+	defer teardown()
+
+	content := fakeContent(fragmentSize + 5)
+	endpoint := &fakeUploadEndpoint{t: t, size: len(content)}
+	mux.Handle("/fake_upload_url", endpoint)
+	_, err := drive.UploadAt(context.Background(), "My Docs/big file.bin", bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("Drive.UploadAt returned error: %v", err)
+	}
+	want := pathRequest{"POST", "/drives/fake_drive_id/root:/My%20Docs/big%20file.bin:/createUploadSession", `{"item":{"@microsoft.graph.conflictBehavior":"rename"}}`}
+	if len(*requests) != 1 || (*requests)[0] != want {
+		t.Errorf("Drive.UploadAt sent %+v, want %+v", *requests, want)
+	}
+	if !bytes.Equal(endpoint.received, content) {
+		t.Errorf("Drive.UploadAt uploaded %d bytes, want the %d bytes of content", len(endpoint.received), len(content))
+	}
+}
@@ -0,0 +1,50 @@
+package onedrive
+
+import (
+	"context"
+	http2 "net/http"
+	"net/url"
+
+	"github.com/bearcatat/onedrive-api/http"
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+type Drives struct {
+	core  *core
+	raw   *resources.Drives
+	Value []*Drive
+}
+
+func newDrives(c *core, raw *resources.Drives) *Drives {
+	drives := make([]*Drive, 0)
+	for i := range raw.Value {
+		drives = append(drives, newDrive(c, &raw.Value[i]))
+	}
+	return &Drives{
+		core:  c,
+		raw:   raw,
+		Value: drives,
+	}
+}
+
+func (d *Drives) HasNext() bool {
+	return d.raw.NextURL != ""
+}
+
+func (d *Drives) Next(ctx context.Context) (*Drives, error) {
+	if !d.HasNext() {
+		return nil, ErrDrivesNoNext
+	}
+
+	var drives *resources.Drives
+	err := d.core.client.DoWithAuth(ctx, d.nextRequest(), &drives)
+	if err != nil {
+		return nil, err
+	}
+	return newDrives(d.core, drives), nil
+}
+
+func (d *Drives) nextRequest() http.Request {
+	url, _ := url.Parse(d.raw.NextURL)
+	return http.NewJsonRequest(http2.MethodGet, url, nil)
+}
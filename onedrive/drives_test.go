@@ -0,0 +1,51 @@
+package onedrive
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+func setup_drives() (drives *Drives, mux *http.ServeMux, teardown func()) {
+	url, mux, teardown := setup()
+	core := newCore(&http.Client{})
+	drives = newDrives(core, &resources.Drives{})
+	drives.raw.NextURL = url.String() + "/next"
+	return drives, mux, teardown
+}
+
+func TestDrives_Next(t *testing.T) {
+	drives, mux, teardown := setup_drives()
+	defer teardown()
+
+	mux.HandleFunc("/next", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		jsonData := readFile(t, "fake_drives.json")
+		w.Write(jsonData)
+	})
+
+	next, err := drives.Next(context.Background())
+	if err != nil {
+		t.Errorf("Drives.Next failed: %v", err)
+	}
+	expectedDrives := getDataFromFile[*resources.Drives](t, "fake_drives.json")
+	if !reflect.DeepEqual(next.raw, expectedDrives) {
+		t.Errorf("Drives.Next returned %+v, want %+v", next.raw, expectedDrives)
+	}
+	if len(next.Value) != 2 || next.Value[1].Name != "Documents" {
+		t.Errorf("Drives.Next returned drives %+v", next.Value)
+	}
+}
+
+func TestDrives_Next_NoNext(t *testing.T) {
+	drives, _, teardown := setup_drives()
+	defer teardown()
+	drives.raw.NextURL = ""
+	_, err := drives.Next(context.Background())
+	if err != ErrDrivesNoNext {
+		t.Errorf("Drives.Next returned %+v, want %+v", err, ErrDrivesNoNext)
+	}
+}
@@ -1,11 +1,57 @@
 package onedrive
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/bearcatat/onedrive-api/http"
+)
 
 var (
-	ErrNotFile             = errors.New("not a file")
-	ErrEmptyFile           = errors.New("empty file")
-	ErrNotFinished         = errors.New("not finished")
-	ErrChildrenNoNext      = errors.New("children has no next")
-	ErrDownloadUrlNotFound = errors.New("download url not found")
+	ErrNotFile              = errors.New("not a file")
+	ErrNotFolder            = errors.New("not a folder")
+	ErrTooManyChildren      = errors.New("folder has more children than the limit")
+	ErrEmptyFile            = errors.New("empty file")
+	ErrNotFinished          = errors.New("not finished")
+	ErrChildrenNoNext       = errors.New("children has no next")
+	ErrDrivesNoNext         = errors.New("drives has no next")
+	ErrDownloadUrlNotFound  = errors.New("download url not found")
+	ErrNotSeekable          = errors.New("source is neither an io.ReaderAt nor an io.ReadSeeker")
+	ErrInvalidUploadSession = errors.New("invalid upload session")
+	ErrUploadIncomplete     = errors.New("upload session has no expected ranges but did not return an item")
+	ErrUploadSessionExpired = errors.New("upload session expired")
+	ErrUploadStalled        = errors.New("upload session stopped accepting bytes")
+	ErrCannotRewind         = errors.New("file cannot be read again from an earlier offset")
+	ErrInvalidFragmentSize  = errors.New("fragment size must be a multiple of 320 KiB and at most 60 MiB")
+	ErrCommitDeferred       = errors.New("upload session is waiting for commit")
+	ErrWriterClosed         = errors.New("upload writer is closed")
+	ErrHashUnavailable      = errors.New("quickXorHash of the file is not available")
+	ErrItemChanged          = errors.New("item changed during the download")
+	ErrInvalidRange         = errors.New("invalid range")
+	ErrInvalidSize          = errors.New("invalid size")
+	ErrRangeIgnored         = http.ErrRangeIgnored
+	ErrFileClosed           = errors.New("file is closed")
+	ErrInvalidSeek          = errors.New("invalid seek")
 )
+
+// GraphError is the error returned when OneDrive drive API rejects a request.
+type GraphError = http.GraphError
+
+// IsNotFound reports whether err is caused by a missing item.
+func IsNotFound(err error) bool {
+	return http.IsNotFound(err)
+}
+
+// IsThrottled reports whether err is caused by throttling.
+func IsThrottled(err error) bool {
+	return http.IsThrottled(err)
+}
+
+// IsConflict reports whether err is caused by a conflicting item, e.g. nameAlreadyExists.
+func IsConflict(err error) bool {
+	return http.IsConflict(err)
+}
+
+// IsQuotaExceeded reports whether err is caused by a full drive.
+func IsQuotaExceeded(err error) bool {
+	return http.IsQuotaExceeded(err)
+}
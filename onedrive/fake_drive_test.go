@@ -0,0 +1,318 @@
+package onedrive
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+// fakeDrive is an in memory drive served like the Graph API. Items are
+// addressed by id and by path from the root, and children are listed in pages
+// of pageSize items.
+type fakeDrive struct {
+	t        *testing.T
+	baseURL  string
+	pageSize int
+
+	mu       sync.Mutex
+	items    map[string]*fakeItem
+	nextId   int
+	requests int
+	queries  []string
+	methods  []string
+}
+
+// fakeItem is a file or folder of a fakeDrive.
+type fakeItem struct {
+	id       string
+	path     string
+	folder   bool
+	content  string
+	modified time.Time
+}
+
+// setup_fake_drive returns a drive served by a fakeDrive holding files, a
+// map of file paths to contents. Paths ending with a slash are folders.
+func setup_fake_drive(t *testing.T, files map[string]string) (drive *Drive, fake *fakeDrive, teardown func()) {
+	drive, mux, teardown := setup_drive()
+	fake = &fakeDrive{
+		t:        t,
+		baseURL:  strings.TrimSuffix(drive.url.baseURL.String(), "/"),
+		pageSize: 2,
+		items:    map[string]*fakeItem{},
+	}
+	fake.add("", true, "")
+	for name, content := range files {
+		fake.add(strings.TrimSuffix(name, "/"), strings.HasSuffix(name, "/"), content)
+	}
+	mux.Handle("/drives/fake_drive_id/", fake)
+	mux.Handle("/fake_content/", fake)
+	return drive, fake, teardown
+}
+
+// add adds an item and its missing parent folders.
+func (d *fakeDrive) add(itemPath string, folder bool, content string) *fakeItem {
+	if itemPath != "" {
+		if parent := parentPath(itemPath); d.byPath(parent) == nil {
+			d.add(parent, true, "")
+		}
+	}
+	item := &fakeItem{
+		id:       "fake_item_" + strconv.Itoa(d.nextId),
+		path:     itemPath,
+		folder:   folder,
+		content:  content,
+		modified: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	d.items[item.id] = item
+	d.nextId++
+	return item
+}
+
+// item returns the item with id, or the root for the id alias "root".
+func (d *fakeDrive) item(id string) *fakeItem {
+	if id == "root" {
+		return d.byPath("")
+	}
+	return d.items[id]
+}
+
+func (d *fakeDrive) byPath(itemPath string) *fakeItem {
+	for _, item := range d.items {
+		if item.path == itemPath {
+			return item
+		}
+	}
+	return nil
+}
+
+// children returns the items in the folder, sorted by path.
+func (d *fakeDrive) children(folder *fakeItem) []*fakeItem {
+	var children []*fakeItem
+	for _, item := range d.items {
+		if item.path != "" && parentPath(item.path) == folder.path {
+			children = append(children, item)
+		}
+	}
+	slices.SortFunc(children, func(a, b *fakeItem) int {
+		return strings.Compare(a.path, b.path)
+	})
+	return children
+}
+
+func (d *fakeDrive) driveItem(item *fakeItem) *resources.DriveItem {
+	driveItem := &resources.DriveItem{
+		Id:                   item.id,
+		Name:                 path.Base(item.path),
+		LastModifiedDateTime: item.modified,
+	}
+	switch {
+	case item.path == "":
+		driveItem.Name = "root"
+		driveItem.Root = &resources.Root{}
+		driveItem.Folder = &resources.Folder{ChildCount: len(d.children(item))}
+	case item.folder:
+		driveItem.Folder = &resources.Folder{ChildCount: len(d.children(item))}
+	default:
+		driveItem.File = &resources.File{}
+		driveItem.Size = int64(len(item.content))
+		driveItem.DownloadURL = d.baseURL + "/fake_content/" + item.id
+	}
+	return driveItem
+}
+
+func (d *fakeDrive) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests++
+	d.queries = append(d.queries, r.URL.RawQuery)
+	d.methods = append(d.methods, r.Method)
+	if id, ok := strings.CutPrefix(r.URL.Path, "/fake_content/"); ok {
+		item := d.items[id]
+		if item == nil || item.folder {
+			d.notFound(w)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(item.content))
+		return
+	}
+	route := strings.TrimPrefix(r.URL.Path, "/drives/fake_drive_id/")
+	switch {
+	case r.Method == http.MethodGet && route == "root":
+		d.writeItem(w, d.byPath(""))
+	case r.Method == http.MethodGet && strings.HasPrefix(route, "root:/"):
+		d.writeItem(w, d.byPath(strings.Trim(strings.TrimPrefix(route, "root:"), "/")))
+	case r.Method == http.MethodGet && strings.HasPrefix(route, "items/") && strings.HasSuffix(route, "/children"):
+		d.writeChildren(w, r, d.item(strings.TrimSuffix(strings.TrimPrefix(route, "items/"), "/children")))
+	case r.Method == http.MethodGet && strings.HasPrefix(route, "items/"):
+		d.writeItem(w, d.item(strings.TrimPrefix(route, "items/")))
+	case r.Method == http.MethodPost && strings.HasPrefix(route, "items/") && strings.HasSuffix(route, "/children"):
+		d.createFolder(w, r, d.item(strings.TrimSuffix(strings.TrimPrefix(route, "items/"), "/children")))
+	case r.Method == http.MethodPut && strings.HasPrefix(route, "items/") && strings.HasSuffix(route, ":/content"):
+		id, name, _ := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(route, "items/"), ":/content"), ":/")
+		d.upload(w, r, d.item(id), name)
+	case r.Method == http.MethodPatch && strings.HasPrefix(route, "items/"):
+		d.update(w, r, d.item(strings.TrimPrefix(route, "items/")))
+	case r.Method == http.MethodDelete && strings.HasPrefix(route, "items/"):
+		d.delete(w, d.item(strings.TrimPrefix(route, "items/")))
+	default:
+		d.t.Errorf("fakeDrive got unexpected request %v %v", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (d *fakeDrive) writeItem(w http.ResponseWriter, item *fakeItem) {
+	if item == nil {
+		d.notFound(w)
+		return
+	}
+	json.NewEncoder(w).Encode(d.driveItem(item))
+}
+
+func (d *fakeDrive) writeChildren(w http.ResponseWriter, r *http.Request, folder *fakeItem) {
+	if folder == nil || !folder.folder {
+		d.notFound(w)
+		return
+	}
+	children := d.children(folder)
+	skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+	pageSize := d.pageSize
+	if top, _ := strconv.Atoi(r.URL.Query().Get("$top")); top > 0 {
+		pageSize = top
+	}
+	page := &resources.Children{}
+	for _, child := range children[skip:min(skip+pageSize, len(children))] {
+		page.Value = append(page.Value, *d.driveItem(child))
+	}
+	if next := skip + pageSize; next < len(children) {
+		query := url.Values{"skip": {strconv.Itoa(next)}, "$top": {strconv.Itoa(pageSize)}}
+		page.NextURL = fmt.Sprintf("%s/drives/fake_drive_id/items/%s/children?%s", d.baseURL, folder.id, query.Encode())
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+func (d *fakeDrive) notFound(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotFound)
+	fmt.Fprint(w, `{"error": {"code": "itemNotFound", "message": "The resource could not be found."}}`)
+}
+
+// create adds an item named name to folder, resolving a name conflict with
+// behavior. It returns nil after writing the error response.
+func (d *fakeDrive) create(w http.ResponseWriter, folder *fakeItem, name string, isFolder bool, behavior string) *fakeItem {
+	if folder == nil || !folder.folder {
+		d.notFound(w)
+		return nil
+	}
+	itemPath := strings.TrimPrefix(path.Join(folder.path, name), "/")
+	if existing := d.byPath(itemPath); existing != nil {
+		switch behavior {
+		case "fail":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"error": {"code": "nameAlreadyExists", "message": "The specified item name already exists."}}`)
+			return nil
+		case "replace":
+			d.remove(existing)
+		default:
+			itemPath += " 1"
+		}
+	}
+	return d.add(itemPath, isFolder, "")
+}
+
+func (d *fakeDrive) createFolder(w http.ResponseWriter, r *http.Request, folder *fakeItem) {
+	var body resources.CreateFolderRequest
+	json.NewDecoder(r.Body).Decode(&body)
+	if item := d.create(w, folder, body.FolderName, true, body.ConflictBehavior); item != nil {
+		w.WriteHeader(http.StatusCreated)
+		d.writeItem(w, item)
+	}
+}
+
+func (d *fakeDrive) upload(w http.ResponseWriter, r *http.Request, folder *fakeItem, name string) {
+	content, _ := io.ReadAll(r.Body)
+	if item := d.create(w, folder, name, false, r.URL.Query().Get("@microsoft.graph.conflictBehavior")); item != nil {
+		item.content = string(content)
+		w.WriteHeader(http.StatusCreated)
+		d.writeItem(w, item)
+	}
+}
+
+// update moves or renames item, and sets the modification time from
+// fileSystemInfo.
+func (d *fakeDrive) update(w http.ResponseWriter, r *http.Request, item *fakeItem) {
+	if item == nil {
+		d.notFound(w)
+		return
+	}
+	var body resources.DriveItem
+	json.NewDecoder(r.Body).Decode(&body)
+	if body.FileSystemInfo != nil && !body.FileSystemInfo.LastModifiedDateTime.IsZero() {
+		item.modified = body.FileSystemInfo.LastModifiedDateTime
+	}
+	if body.ParentReference != nil || body.Name != "" {
+		parent := parentPath(item.path)
+		if body.ParentReference != nil {
+			folder := d.items[body.ParentReference.ID]
+			if folder == nil {
+				d.notFound(w)
+				return
+			}
+			parent = folder.path
+		}
+		name := cmp.Or(body.Name, path.Base(item.path))
+		newPath := strings.TrimPrefix(path.Join(parent, name), "/")
+		if existing := d.byPath(newPath); existing != nil && r.URL.Query().Get("@microsoft.graph.conflictBehavior") == "replace" && !existing.folder {
+			d.remove(existing)
+		} else if existing != nil {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"error": {"code": "nameAlreadyExists", "message": "The specified item name already exists."}}`)
+			return
+		}
+		for _, other := range d.items {
+			if strings.HasPrefix(other.path, item.path+"/") {
+				other.path = newPath + strings.TrimPrefix(other.path, item.path)
+			}
+		}
+		item.path = newPath
+	}
+	d.writeItem(w, item)
+}
+
+func (d *fakeDrive) delete(w http.ResponseWriter, item *fakeItem) {
+	if item == nil {
+		d.notFound(w)
+		return
+	}
+	d.remove(item)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// remove deletes item and everything it contains.
+func (d *fakeDrive) remove(item *fakeItem) {
+	for id, other := range d.items {
+		if other == item || strings.HasPrefix(other.path, item.path+"/") {
+			delete(d.items, id)
+		}
+	}
+}
+
+// parentPath returns the path of the folder holding itemPath, "" for the
+// root.
+func parentPath(itemPath string) string {
+	if dir := path.Dir(itemPath); dir != "." {
+		return dir
+	}
+	return ""
+}
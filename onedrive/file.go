@@ -2,14 +2,21 @@ package onedrive
 
 import (
 	"io"
-	"net/url"
-
-	"github.com/bearcatat/onedrive-api/http"
-	"github.com/bearcatat/onedrive-api/resources"
 )
 
 const (
+	// fragmentSize is the default size of upload session fragments.
 	fragmentSize = 10 * 1024 * 1024
+	// simpleUploadLimit is the largest file that can be uploaded with a single PUT request.
+	simpleUploadLimit = 4 * 1024 * 1024
+	// maxFragmentRetries is how many times a failed fragment is sent again.
+	maxFragmentRetries = 3
+	// maxStalledFragments is how many fragments in a row may be sent without
+	// the server accepting any byte of them.
+	maxStalledFragments = 3
+	// maxSessionRestarts is how many times an upload starts over in a new
+	// session after its session expired.
+	maxSessionRestarts = 1
 )
 
 type File interface {
@@ -18,54 +25,182 @@ type File interface {
 	IsDir() bool
 	Size() int64
 }
-type fileForUpload struct {
-	f            File
-	size         int64
-	uploadedSize int64
-	url          *url.URL
+
+// readerFile adapts a reader of known size to File.
+type readerFile struct {
+	io.Reader
+	name string
+	size int64
 }
 
-func newFileForUpload(f File, uploadSession *resources.UploadSession) *fileForUpload {
-	file := &fileForUpload{
-		f:            f,
-		uploadedSize: 0,
+func newReaderFile(name string, r io.Reader, size int64) *readerFile {
+	return &readerFile{
+		Reader: r,
+		name:   name,
+		size:   size,
 	}
-	file.url, _ = url.Parse(uploadSession.UploadURL)
-	file.size = f.Size()
-	return file
 }
 
-func (f *fileForUpload) next() bool {
-	return f.uploadedSize < f.size
+func (f *readerFile) Name() string {
+	return f.name
+}
+
+func (f *readerFile) IsDir() bool {
+	return false
+}
+
+func (f *readerFile) Size() int64 {
+	return f.size
+}
+
+// fragmentSource provides the fragments of a file being uploaded.
+type fragmentSource interface {
+	// fragmentAt returns the next fragment of at most maxLength bytes
+	// starting at offset.
+	fragmentAt(offset, maxLength int64) ([]byte, error)
+	// rewind prepares the source to be uploaded again from the start.
+	rewind() error
+}
+
+// fileForUpload reads a File sequentially. It keeps the last fragment so that
+// bytes the server did not accept can be sent again.
+type fileForUpload struct {
+	f           File
+	size        int64
+	window      []byte
+	windowStart int64
 }
 
-func (f *fileForUpload) getNextRequest() (http.Request, error) {
-	fragment, err := f.readFragment()
+func newFileForUpload(f File) *fileForUpload {
+	return &fileForUpload{
+		f:    f,
+		size: f.Size(),
+	}
+}
+
+func (f *fileForUpload) fragmentAt(offset, maxLength int64) ([]byte, error) {
+	if offset >= f.size {
+		return nil, ErrUploadIncomplete
+	}
+	if offset < f.windowStart {
+		return nil, ErrCannotRewind
+	}
+	windowEnd := f.windowStart + int64(len(f.window))
+	if offset > windowEnd {
+		if _, err := io.CopyN(io.Discard, f.f, offset-windowEnd); err != nil {
+			return nil, err
+		}
+		f.window, f.windowStart = nil, offset
+		windowEnd = offset
+	}
+	length := min(maxLength, f.size-offset)
+	kept := f.window[offset-f.windowStart:]
+	if int64(len(kept)) >= length {
+		return kept[:length], nil
+	}
+	more, err := f.read(int(length) - len(kept))
 	if err != nil {
 		return nil, err
 	}
-	req := http.NewFileFragmentUploadRequest(*f.url, f.uploadedSize, f.f.Size(), fragment)
-	f.uploadedSize += int64(len(fragment))
-	return req, nil
+	fragment := make([]byte, 0, length)
+	fragment = append(fragment, kept...)
+	fragment = append(fragment, more...)
+	f.window, f.windowStart = fragment, offset
+	return fragment, nil
+}
+
+func (f *fileForUpload) rewind() error {
+	seeker, ok := seekerOf(f.f)
+	if !ok {
+		return ErrCannotRewind
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	f.window, f.windowStart = nil, 0
+	return nil
 }
 
-func (f *fileForUpload) readFragment() ([]byte, error) {
-	buffer := make([]byte, fragmentSize)
+// read reads the next size bytes of the file.
+func (f *fileForUpload) read(size int) ([]byte, error) {
+	buffer := make([]byte, size)
 	n := 0
-	for n < fragmentSize {
+	for n < size {
 		sn, err := f.f.Read(buffer[n:])
+		n = min(n+sn, size)
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			if err == io.EOF {
-				n += sn
-				break
-			}
 			return nil, err
 		}
-		n += sn
 	}
-	if n < fragmentSize {
-		bufferLast := buffer[:n]
-		buffer = bufferLast
+	if n < size {
+		return nil, io.ErrUnexpectedEOF
 	}
 	return buffer, nil
 }
+
+func seekerOf(f File) (io.Seeker, bool) {
+	if r, ok := f.(*readerFile); ok {
+		seeker, ok := r.Reader.(io.Seeker)
+		return seeker, ok
+	}
+	seeker, ok := f.(io.Seeker)
+	return seeker, ok
+}
+
+// readerAtSource reads fragments of a file at any offset.
+type readerAtSource struct {
+	r    io.ReaderAt
+	size int64
+}
+
+func newReaderAtSource(r io.ReaderAt, size int64) *readerAtSource {
+	return &readerAtSource{
+		r:    r,
+		size: size,
+	}
+}
+
+func (s *readerAtSource) fragmentAt(offset, maxLength int64) ([]byte, error) {
+	length := min(maxLength, s.size-offset)
+	if length <= 0 {
+		return nil, ErrUploadIncomplete
+	}
+	fragment := make([]byte, length)
+	n, err := s.r.ReadAt(fragment, offset)
+	if n == len(fragment) {
+		return fragment, nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return nil, err
+}
+
+func (s *readerAtSource) rewind() error {
+	return nil
+}
+
+// bufferSource serves fragments from the bytes of a file held in memory,
+// which start at offset.
+type bufferSource struct {
+	data   []byte
+	offset int64
+}
+
+func (b *bufferSource) fragmentAt(offset, maxLength int64) ([]byte, error) {
+	if offset < b.offset {
+		return nil, ErrCannotRewind
+	}
+	start := offset - b.offset
+	if start >= int64(len(b.data)) {
+		return nil, ErrUploadIncomplete
+	}
+	return b.data[start:min(start+maxLength, int64(len(b.data)))], nil
+}
+
+func (b *bufferSource) rewind() error {
+	return ErrCannotRewind
+}
@@ -0,0 +1,231 @@
+package onedrive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bearcatat/onedrive-api/vfs"
+)
+
+// FS is a file system over the items of a drive. Paths are resolved against
+// the root of the drive, or the folder FS.Sub returned the FS for.
+type FS struct {
+	ctx   context.Context
+	drive *Drive
+	root  string
+}
+
+var (
+	_ fs.ReadDirFS = (*FS)(nil)
+	_ fs.StatFS    = (*FS)(nil)
+	_ fs.SubFS     = (*FS)(nil)
+
+	_ vfs.FileSystem = (*FS)(nil)
+)
+
+// FS returns a file system over the items of the drive. ctx applies to all
+// requests made through the file system and its files.
+func (d *Drive) FS(ctx context.Context) *FS {
+	return &FS{ctx: ctx, drive: d}
+}
+
+// Open opens the file or folder at name. Files are read with ranged requests
+// through a RemoteFile.
+func (f *FS) Open(name string) (fs.File, error) {
+	item, err := f.item("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if isDir(item) {
+		return &dirFile{fs: f, name: name, item: item}, nil
+	}
+	return newRemoteFile(f.ctx, item, openOptions(nil)), nil
+}
+
+// ReadDir lists the folder at name, sorted by name.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	item, err := f.item("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !isDir(item) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: vfs.ErrNotDir}
+	}
+	entries, err := readDir(f.ctx, item)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return entries, nil
+}
+
+// Stat returns the file info of the item at name.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	item, err := f.item("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fileInfo{item}, nil
+}
+
+// Sub returns the file system rooted at the folder dir. The folder is not
+// checked to exist.
+func (f *FS) Sub(dir string) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrInvalid}
+	}
+	return &FS{ctx: f.ctx, drive: f.drive, root: path.Join(f.root, dir)}, nil
+}
+
+// item fetches the item at name for op.
+func (f *FS) item(op, name string) (*DriveItem, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	item, err := f.lookup(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return item, nil
+}
+
+// lookup fetches the item at the valid path name.
+func (f *FS) lookup(name string) (*DriveItem, error) {
+	item, err := f.drive.GetByPath(f.ctx, f.path(name))
+	return item, fsError(err)
+}
+
+// path returns the path of name from the root of the drive, "" for the root
+// itself.
+func (f *FS) path(name string) string {
+	if p := path.Join(f.root, name); p != "." {
+		return p
+	}
+	return ""
+}
+
+// fsError maps errors of the Graph API to the errors of io/fs.
+func fsError(err error) error {
+	switch {
+	case IsNotFound(err):
+		return fs.ErrNotExist
+	case IsConflict(err):
+		return fs.ErrExist
+	case errors.Is(err, ErrNotFolder):
+		return vfs.ErrNotDir
+	}
+	return err
+}
+
+func isDir(item *DriveItem) bool {
+	if remote := item.DriveItem.RemoteItem; remote != nil {
+		return remote.Folder != nil
+	}
+	return item.Folder != nil || item.Root != nil
+}
+
+// readDir lists all pages of the children of item, sorted by name.
+func readDir(ctx context.Context, item *DriveItem) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	for child, err := range item.Children(ctx).All() {
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(fileInfo{child}))
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// fileInfo describes a DriveItem as an fs.FileInfo.
+type fileInfo struct {
+	item *DriveItem
+}
+
+func (fi fileInfo) Name() string {
+	return fi.item.Name
+}
+
+func (fi fileInfo) Size() int64 {
+	return fi.item.DriveItem.Size
+}
+
+func (fi fileInfo) Mode() fs.FileMode {
+	if isDir(fi.item) {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// ModTime returns the time the item was modified on the client when known.
+func (fi fileInfo) ModTime() time.Time {
+	if info := fi.item.FileSystemInfo; info != nil && !info.LastModifiedDateTime.IsZero() {
+		return info.LastModifiedDateTime
+	}
+	return fi.item.LastModifiedDateTime
+}
+
+func (fi fileInfo) IsDir() bool {
+	return isDir(fi.item)
+}
+
+// Sys returns the *DriveItem.
+func (fi fileInfo) Sys() any {
+	return fi.item
+}
+
+// dirFile is an open folder. Its children are listed on the first ReadDir.
+type dirFile struct {
+	fs      *FS
+	name    string
+	item    *DriveItem
+	entries []fs.DirEntry
+	listed  bool
+	offset  int
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) {
+	return fileInfo{d.item}, nil
+}
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: vfs.ErrIsDir}
+}
+
+func (d *dirFile) Write([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "write", Path: d.name, Err: vfs.ErrIsDir}
+}
+
+func (d *dirFile) Close() error {
+	return nil
+}
+
+// ReadDir returns the next n children, or all remaining children when n <= 0.
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.listed {
+		entries, err := readDir(d.fs.ctx, d.item)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries = entries
+		d.listed = true
+	}
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
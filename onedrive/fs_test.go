@@ -0,0 +1,142 @@
+package onedrive
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/bearcatat/onedrive-api/vfs"
+	"github.com/bearcatat/onedrive-api/vfs/vfstest"
+)
+
+var fakeFiles = map[string]string{
+	"hello.txt":           "hello world!",
+	"empty.txt":           "",
+	".env":                "SECRET=1",
+	".config/app.toml":    "debug = true",
+	"docs/a.md":           "# A",
+	"docs/b.md":           "# B",
+	"docs/c.md":           "# C",
+	"docs/nested/d.md":    "# D",
+	"docs/empty/":         "",
+	"photos/2024/img.raw": string(fakeContent(3000)),
+}
+
+func TestFS(t *testing.T) {
+	drive, _, teardown := setup_fake_drive(t, fakeFiles)
+	defer teardown()
+
+	fsys := drive.FS(context.Background())
+	err := fstest.TestFS(fsys, "hello.txt", "empty.txt", ".env", ".config/app.toml", "docs/a.md", "docs/b.md", "docs/c.md", "docs/nested/d.md", "docs/empty", "photos/2024/img.raw")
+	if err != nil {
+		t.Error(err)
+	}
+
+	sub, err := fs.Sub(fsys, "docs")
+	if err != nil {
+		t.Fatalf("FS.Sub returned error: %v", err)
+	}
+	if err := fstest.TestFS(sub, "a.md", "b.md", "c.md", "nested/d.md", "empty"); err != nil {
+		t.Error(err)
+	}
+
+	dotSub, err := fs.Sub(fsys, ".config")
+	if err != nil {
+		t.Fatalf("FS.Sub returned error: %v", err)
+	}
+	if err := fstest.TestFS(dotSub, "app.toml"); err != nil {
+		t.Error(err)
+	}
+	content, err := fs.ReadFile(fsys, ".env")
+	if err != nil || string(content) != "SECRET=1" {
+		t.Errorf("fs.ReadFile(.env) returned %q, %v, want %q", content, err, "SECRET=1")
+	}
+}
+
+func TestFS_Glob(t *testing.T) {
+	drive, _, teardown := setup_fake_drive(t, fakeFiles)
+	defer teardown()
+
+	matches, err := fs.Glob(drive.FS(context.Background()), "docs/*.md")
+	if err != nil || len(matches) != 3 || matches[0] != "docs/a.md" {
+		t.Errorf("fs.Glob returned %v, %v, want %v", matches, err, []string{"docs/a.md", "docs/b.md", "docs/c.md"})
+	}
+}
+
+func TestFS_Stat(t *testing.T) {
+	drive, _, teardown := setup_fake_drive(t, fakeFiles)
+	defer teardown()
+
+	fsys := drive.FS(context.Background())
+	info, err := fsys.Stat("hello.txt")
+	if err != nil {
+		t.Fatalf("FS.Stat returned error: %v", err)
+	}
+	if info.Name() != "hello.txt" || info.Size() != 12 || info.IsDir() || info.Mode() != 0444 {
+		t.Errorf("FS.Stat returned %v %v %v %v", info.Name(), info.Size(), info.IsDir(), info.Mode())
+	}
+	if item, ok := info.Sys().(*DriveItem); !ok || item.Name != "hello.txt" {
+		t.Errorf("FileInfo.Sys returned %+v, want the *DriveItem", info.Sys())
+	}
+
+	_, err = fsys.Stat("missing.txt")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("FS.Stat returned %v, want %v", err, fs.ErrNotExist)
+	}
+	_, err = fsys.Stat("/hello.txt")
+	if !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("FS.Stat returned %v, want %v", err, fs.ErrInvalid)
+	}
+	_, err = fsys.ReadDir("hello.txt")
+	if err == nil {
+		t.Errorf("FS.ReadDir of a file returned no error")
+	}
+}
+
+func TestFS_FileSystem(t *testing.T) {
+	vfstest.TestFileSystem(t, func(t *testing.T) vfs.FileSystem {
+		drive, _, teardown := setup_fake_drive(t, nil)
+		t.Cleanup(teardown)
+		return drive.FS(context.Background())
+	})
+}
+
+func TestFS_OpenFile_Unsupported(t *testing.T) {
+	drive, _, teardown := setup_fake_drive(t, fakeFiles)
+	defer teardown()
+
+	fsys := drive.FS(context.Background())
+	for _, flag := range []int{os.O_RDWR, os.O_WRONLY | os.O_APPEND, os.O_WRONLY} {
+		_, err := fsys.OpenFile("hello.txt", flag, 0666)
+		if !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("FS.OpenFile(%#x) returned %v, want %v", flag, err, errors.ErrUnsupported)
+		}
+	}
+}
+
+func TestFS_Rename_Replace(t *testing.T) {
+	drive, fake, teardown := setup_fake_drive(t, map[string]string{"a.txt": "new", "b.txt": "old"})
+	defer teardown()
+
+	fsys := drive.FS(context.Background())
+	if err := fsys.Rename("a.txt", "b.txt"); err != nil {
+		t.Fatalf("FS.Rename returned error: %v", err)
+	}
+	content, err := fs.ReadFile(fsys, "b.txt")
+	if err != nil || string(content) != "new" {
+		t.Errorf("FS.Rename left b.txt with %q, %v, want %q", content, err, "new")
+	}
+	if _, err := fsys.Stat("a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("FS.Stat of the renamed file returned %v, want %v", err, fs.ErrNotExist)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if slices.Contains(fake.methods, http.MethodDelete) {
+		t.Errorf("FS.Rename sent %v, want the target replaced by the move", fake.methods)
+	}
+}
@@ -0,0 +1,272 @@
+package onedrive
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bearcatat/onedrive-api/resources"
+	"github.com/bearcatat/onedrive-api/vfs"
+)
+
+// Create creates or replaces the file name. The file is uploaded as it is
+// written and created when closed.
+func (f *FS) Create(name string) (vfs.File, error) {
+	return f.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// OpenFile opens name for reading with os.O_RDONLY, or for writing with
+// os.O_WRONLY and os.O_TRUNC, os.O_CREATE or os.O_EXCL. Files are written
+// sequentially through an UploadWriter and replaced when closed. Other flags
+// fail with errors.ErrUnsupported. perm is ignored.
+func (f *FS) OpenFile(name string, flag int, perm fs.FileMode) (vfs.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		file, err := f.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		if remote, ok := file.(*RemoteFile); ok {
+			return &readOnlyFile{RemoteFile: remote, name: name}, nil
+		}
+		return file.(*dirFile), nil
+	}
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if flag&(os.O_RDWR|os.O_APPEND) != 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.ErrUnsupported}
+	}
+	writer, err := f.createWriter(name, flag)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &writeFile{name: name, writer: writer}, nil
+}
+
+func (f *FS) createWriter(name string, flag int) (*UploadWriter, error) {
+	item, err := f.lookup(name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && flag&os.O_CREATE == 0:
+		return nil, err
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, fs.ErrExist
+	case isDir(item):
+		return nil, vfs.ErrIsDir
+	case flag&os.O_TRUNC == 0:
+		// The content of a file can only be replaced as a whole.
+		return nil, errors.ErrUnsupported
+	}
+	parent, err := f.folder(path.Dir(name))
+	if err != nil {
+		return nil, err
+	}
+	options := &UploadOptions{ConflictBehavior: resources.ConflictBehaviorReplace}
+	if flag&os.O_EXCL != 0 {
+		options.ConflictBehavior = resources.ConflictBehaviorFail
+	}
+	return parent.CreateWriter(f.ctx, path.Base(name), options)
+}
+
+// folder fetches the folder at the valid path name.
+func (f *FS) folder(name string) (*DriveItem, error) {
+	item, err := f.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if !isDir(item) {
+		return nil, vfs.ErrNotDir
+	}
+	return item, nil
+}
+
+// Mkdir creates the folder name. perm is ignored.
+func (f *FS) Mkdir(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	_, err := f.lookup(name)
+	if err == nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		var parent *DriveItem
+		parent, err = f.folder(path.Dir(name))
+		if err == nil {
+			_, err = parent.CreateFolder(f.ctx, path.Base(name))
+		}
+	}
+	if err != nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fsError(err)}
+	}
+	return nil
+}
+
+// MkdirAll creates the folder name and its missing parents with
+// Drive.MkdirAll. perm is ignored.
+func (f *FS) MkdirAll(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	if _, err := f.drive.MkdirAll(f.ctx, f.path(name)); err != nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fsError(err)}
+	}
+	return nil
+}
+
+// Remove deletes the file or empty folder name.
+func (f *FS) Remove(name string) error {
+	if name == "." {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+	item, err := f.item("remove", name)
+	if err != nil {
+		return err
+	}
+	if childCount(item) > 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: vfs.ErrNotEmpty}
+	}
+	f.drive.folders.forget(f.path(name))
+	if err := item.Delete(f.ctx); err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: fsError(err)}
+	}
+	return nil
+}
+
+// RemoveAll deletes name and everything it contains with a single request.
+func (f *FS) RemoveAll(name string) error {
+	if name == "." {
+		return &fs.PathError{Op: "removeall", Path: name, Err: fs.ErrInvalid}
+	}
+	item, err := f.item("removeall", name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	f.drive.folders.forget(f.path(name))
+	if err := item.Delete(f.ctx); err != nil && !IsNotFound(err) {
+		return &fs.PathError{Op: "removeall", Path: name, Err: fsError(err)}
+	}
+	return nil
+}
+
+func childCount(item *DriveItem) int {
+	if remote := item.DriveItem.RemoteItem; remote != nil && remote.Folder != nil {
+		return remote.Folder.ChildCount
+	}
+	if item.Folder != nil {
+		return item.Folder.ChildCount
+	}
+	return 0
+}
+
+// Rename moves oldname to newname, replacing a file at newname. The file is
+// replaced by the move itself, so it is kept when the move fails.
+func (f *FS) Rename(oldname, newname string) error {
+	if err := f.rename(oldname, newname); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fsError(err)}
+	}
+	return nil
+}
+
+func (f *FS) rename(oldname, newname string) error {
+	if !fs.ValidPath(oldname) || !fs.ValidPath(newname) || oldname == "." || newname == "." ||
+		strings.HasPrefix(newname, oldname+"/") {
+		return fs.ErrInvalid
+	}
+	item, err := f.lookup(oldname)
+	if err != nil {
+		return err
+	}
+	parent, err := f.folder(path.Dir(newname))
+	if err != nil {
+		return err
+	}
+	target, err := f.lookup(newname)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	case isDir(target) || isDir(item):
+		return fs.ErrExist
+	}
+	f.drive.folders.forget(f.path(oldname))
+	_, err = item.moveReplacing(f.ctx, parent, path.Base(newname))
+	return err
+}
+
+// Chtimes sets the times the item name was last accessed and modified on the
+// client, as kept in its fileSystemInfo.
+func (f *FS) Chtimes(name string, atime, mtime time.Time) error {
+	item, err := f.item("chtimes", name)
+	if err != nil {
+		return err
+	}
+	update := newDriveItem(f.drive.core, &resources.DriveItem{
+		FileSystemInfo: &resources.FileSystemInfo{
+			LastAccessedDateTime: atime,
+			LastModifiedDateTime: mtime,
+		},
+	}, f.drive.Drive)
+	if _, err := item.Update(f.ctx, update); err != nil {
+		return &fs.PathError{Op: "chtimes", Path: name, Err: fsError(err)}
+	}
+	return nil
+}
+
+// readOnlyFile is a file opened by OpenFile for reading.
+type readOnlyFile struct {
+	*RemoteFile
+	name string
+}
+
+func (r *readOnlyFile) Write([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "write", Path: r.name, Err: fs.ErrPermission}
+}
+
+// writeFile is a file opened by OpenFile for writing.
+type writeFile struct {
+	name    string
+	writer  *UploadWriter
+	written int64
+}
+
+// Stat returns the uploaded file once closed, and the bytes written so far
+// before.
+func (w *writeFile) Stat() (fs.FileInfo, error) {
+	if item := w.writer.Item(); item != nil {
+		return fileInfo{item}, nil
+	}
+	return fileInfo{&DriveItem{DriveItem: &resources.DriveItem{
+		Name: path.Base(w.name),
+		Size: w.written,
+		File: &resources.File{},
+	}}}, nil
+}
+
+func (w *writeFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: w.name, Err: fs.ErrPermission}
+}
+
+func (w *writeFile) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	if err != nil {
+		return n, &fs.PathError{Op: "write", Path: w.name, Err: fsError(err)}
+	}
+	return n, nil
+}
+
+func (w *writeFile) Close() error {
+	if err := w.writer.Close(); err != nil {
+		return &fs.PathError{Op: "close", Path: w.name, Err: fsError(err)}
+	}
+	return nil
+}
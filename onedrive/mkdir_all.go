@@ -0,0 +1,152 @@
+package onedrive
+
+import (
+	"context"
+	http2 "net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/bearcatat/onedrive-api/http"
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+// MkdirAll returns the folder at folderPath, creating it and its missing
+// parents. Folders are created with the fail conflict behavior, so a folder
+// created at the same time by another caller is used instead of a renamed
+// copy. The folders resolved are cached by the drive, so concurrent calls
+// for overlapping paths look up or create every folder once.
+func (d *Drive) MkdirAll(ctx context.Context, folderPath string) (*DriveItem, error) {
+	folderPath = strings.Trim(path.Clean("/"+folderPath), "/")
+	if folderPath == "" {
+		return d.GetByPath(ctx, "")
+	}
+	item, err := d.folder(ctx, folderPath)
+	if IsNotFound(err) {
+		// A cached folder was deleted: resolve the path again.
+		d.folders.forget("")
+		item, err = d.folder(ctx, folderPath)
+	}
+	return item, err
+}
+
+// folder resolves or creates the folder at the clean path folderPath. Only
+// one lookup per path is in flight at a time.
+func (d *Drive) folder(ctx context.Context, folderPath string) (*DriveItem, error) {
+	if folderPath == "" {
+		return newDriveItem(d.core, &resources.DriveItem{Id: "root"}, d.Drive), nil
+	}
+	return d.folders.do(ctx, folderPath, func() (*DriveItem, error) {
+		item, err := d.GetByPath(ctx, folderPath)
+		if IsNotFound(err) {
+			item, err = d.createFolder(ctx, folderPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !isDir(item) {
+			return nil, ErrNotFolder
+		}
+		return item, nil
+	})
+}
+
+// createFolder creates the folder at folderPath in its parent, and fetches
+// the folder when it was created concurrently.
+func (d *Drive) createFolder(ctx context.Context, folderPath string) (*DriveItem, error) {
+	dir, name := path.Split(folderPath)
+	parent, err := d.folder(ctx, strings.TrimSuffix(dir, "/"))
+	if err != nil {
+		return nil, err
+	}
+	var driveItem *resources.DriveItem
+	err = d.client.DoWithAuth(ctx, parent.createFolderFailRequest(name), &driveItem)
+	if IsConflict(err) {
+		return d.GetByPath(ctx, folderPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newDriveItem(d.core, driveItem, d.Drive), nil
+}
+
+func (i *DriveItem) createFolderFailRequest(folderName string) http.Request {
+	url := i.url.CreateFolder(i.targetDrive().Id, i.targetId())
+	body := resources.NewCreateFolderRequest(folderName)
+	body.ConflictBehavior = string(resources.ConflictBehaviorFail)
+	return http.NewJsonRequest(http2.MethodPost, url, body)
+}
+
+// folderCache maps the paths of folders to the folders. Its zero value is
+// empty.
+type folderCache struct {
+	mu      sync.Mutex
+	folders map[string]*DriveItem
+	calls   map[string]*folderCall
+}
+
+// folderCall is a lookup in flight.
+type folderCall struct {
+	done     chan struct{}
+	item     *DriveItem
+	err      error
+	canceled bool
+}
+
+// do returns the cached folder at folderPath, or resolves it with resolve,
+// which runs with ctx. Callers of the same path wait for the lookup in
+// flight until their own ctx is done. When the lookup fails because the
+// context of its caller is done, a waiter resolves the path again.
+func (c *folderCache) do(ctx context.Context, folderPath string, resolve func() (*DriveItem, error)) (*DriveItem, error) {
+	for {
+		c.mu.Lock()
+		if item, ok := c.folders[folderPath]; ok {
+			c.mu.Unlock()
+			return item, nil
+		}
+		call, ok := c.calls[folderPath]
+		if !ok {
+			break
+		}
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-call.done:
+		}
+		if !call.canceled {
+			return call.item, call.err
+		}
+	}
+	call := &folderCall{done: make(chan struct{})}
+	if c.calls == nil {
+		c.calls = map[string]*folderCall{}
+		c.folders = map[string]*DriveItem{}
+	}
+	c.calls[folderPath] = call
+	c.mu.Unlock()
+
+	call.item, call.err = resolve()
+	call.canceled = call.err != nil && ctx.Err() != nil
+	c.mu.Lock()
+	delete(c.calls, folderPath)
+	if call.err == nil {
+		c.folders[folderPath] = call.item
+	}
+	c.mu.Unlock()
+	close(call.done)
+	return call.item, call.err
+}
+
+// forget drops the folder at itemPath and the folders below it, or all
+// folders for an empty path.
+func (c *folderCache) forget(itemPath string) {
+	itemPath = strings.Trim(path.Clean("/"+itemPath), "/")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for folderPath := range c.folders {
+		if itemPath == "" || folderPath == itemPath || strings.HasPrefix(folderPath, itemPath+"/") {
+			delete(c.folders, folderPath)
+		}
+	}
+}
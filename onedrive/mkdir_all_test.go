@@ -0,0 +1,143 @@
+package onedrive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDrive_MkdirAll(t *testing.T) {
+	drive, fake, teardown := setup_fake_drive(t, map[string]string{"a/": "", "a/file.txt": "hello"})
+	defer teardown()
+
+	ctx := context.Background()
+	item, err := drive.MkdirAll(ctx, "/a/b/c/")
+	if err != nil {
+		t.Fatalf("Drive.MkdirAll returned error: %v", err)
+	}
+	if item.Name != "c" || item.Folder == nil {
+		t.Errorf("Drive.MkdirAll returned %+v, want the folder c", item.DriveItem)
+	}
+	if fake.byPath("a/b") == nil || fake.byPath("a/b/c") == nil {
+		t.Errorf("Drive.MkdirAll did not create a/b/c")
+	}
+
+	// The folders are cached.
+	requests := fake.requests
+	again, err := drive.MkdirAll(ctx, "a/b/c")
+	if err != nil || again.Id != item.Id || fake.requests != requests {
+		t.Errorf("Drive.MkdirAll returned %+v, %v after %d requests, want the cached folder", again, err, fake.requests-requests)
+	}
+
+	_, err = drive.MkdirAll(ctx, "a/file.txt/d")
+	if err != ErrNotFolder {
+		t.Errorf("Drive.MkdirAll returned %v, want %v", err, ErrNotFolder)
+	}
+
+	root, err := drive.MkdirAll(ctx, "")
+	if err != nil || root.Root == nil {
+		t.Errorf("Drive.MkdirAll returned %+v, %v, want the root", root, err)
+	}
+}
+
+func TestDrive_MkdirAll_Concurrent(t *testing.T) {
+	drive, fake, teardown := setup_fake_drive(t, nil)
+	defer teardown()
+
+	// Half of the callers share a drive and its cache, the others use a drive
+	// of their own and race to create the same folders.
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := range 20 {
+		caller := drive
+		if i%2 == 1 {
+			caller = newDrive(drive.core, drive.Drive)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := caller.MkdirAll(context.Background(), fmt.Sprintf("x/y/%d/z", i%4))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Drive.MkdirAll returned error: %v", err)
+		}
+	}
+	var paths []string
+	for _, item := range fake.items {
+		paths = append(paths, item.path)
+		if strings.HasSuffix(item.path, " 1") {
+			t.Errorf("Drive.MkdirAll created the duplicate %v", item.path)
+		}
+	}
+	if len(paths) != 1+2+4*2 {
+		t.Errorf("Drive.MkdirAll created %v, want x, x/y and x/y/{0..3}/z", paths)
+	}
+}
+
+func TestDrive_MkdirAll_DeletedFolder(t *testing.T) {
+	drive, fake, teardown := setup_fake_drive(t, nil)
+	defer teardown()
+
+	ctx := context.Background()
+	if _, err := drive.MkdirAll(ctx, "a/b"); err != nil {
+		t.Fatalf("Drive.MkdirAll returned error: %v", err)
+	}
+	// Delete the cached folders behind the back of the drive.
+	fake.remove(fake.byPath("a"))
+
+	if _, err := drive.MkdirAll(ctx, "a/b/c"); err != nil {
+		t.Fatalf("Drive.MkdirAll returned error: %v", err)
+	}
+	if fake.byPath("a/b/c") == nil {
+		t.Errorf("Drive.MkdirAll did not create a/b/c again")
+	}
+}
+
+func TestFolderCache_Do_CanceledCaller(t *testing.T) {
+	var cache folderCache
+	started := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go cache.do(ctx, "a", func() (*DriveItem, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	<-started
+
+	// A waiter whose own context is done returns at once.
+	waiterCtx, waiterCancel := context.WithCancel(context.Background())
+	waiterCancel()
+	if _, err := cache.do(waiterCtx, "a", nil); err != context.Canceled {
+		t.Errorf("folderCache.do returned %v, want %v", err, context.Canceled)
+	}
+
+	// A waiter does not get the error of the canceled caller: it resolves
+	// the path again.
+	want := &DriveItem{}
+	done := make(chan error, 1)
+	go func() {
+		item, err := cache.do(context.Background(), "a", func() (*DriveItem, error) { return want, nil })
+		if item != want {
+			t.Errorf("folderCache.do returned %+v, want %+v", item, want)
+		}
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("folderCache.do returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("folderCache.do did not return")
+	}
+}
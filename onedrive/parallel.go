@@ -0,0 +1,237 @@
+package onedrive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	http2 "net/http"
+	"net/url"
+	"sync"
+
+	"github.com/bearcatat/onedrive-api/http"
+	"github.com/bearcatat/onedrive-api/quickxorhash"
+)
+
+const (
+	// parallelChunkSize is the default size of the ranges of a parallel download.
+	parallelChunkSize = 10 * 1024 * 1024
+	// parallelConcurrency is the default number of ranges downloaded at once.
+	parallelConcurrency = 4
+	// maxChunkRetries is how many times a failed range is downloaded again.
+	maxChunkRetries = 3
+)
+
+// DownloadParallel downloads the content of the item into w over several
+// connections. The content is split in ranges of DownloadOptions.ChunkSize
+// bytes, fetched by DownloadOptions.Concurrency workers and written at their
+// offset. A failed range is downloaded again on its own. Progress reports the
+// bytes of all ranges. Verify requires w to be an io.ReaderAt as well, such as
+// an *os.File, to read the content back once complete.
+func (i *DriveItem) DownloadParallel(ctx context.Context, w io.WriterAt, opts ...*DownloadOptions) error {
+	options := downloadOptions(opts)
+	if options.Verify && (i.File == nil || i.File.Hashes.QuickXorHash == "") {
+		return ErrHashUnavailable
+	}
+	readerAt, canVerify := w.(io.ReaderAt)
+	if options.Verify && !canVerify {
+		return ErrHashUnavailable
+	}
+	download := &parallelDownload{
+		item:        i,
+		w:           w,
+		transfer:    newTransfer(i.DriveItem.Size, options.Progress, options.RateLimiter, i.downloadLimiter),
+		downloadURL: i.DriveItem.DownloadURL,
+	}
+	if err := download.run(ctx, options.chunkSize(), options.concurrency()); err != nil {
+		return err
+	}
+	if !options.Verify {
+		return nil
+	}
+	local := quickxorhash.New()
+	if _, err := io.Copy(local, io.NewSectionReader(readerAt, 0, i.DriveItem.Size)); err != nil {
+		return err
+	}
+	return verifyHash(local, i.File)
+}
+
+func (o *DownloadOptions) chunkSize() int64 {
+	if o.ChunkSize <= 0 {
+		return parallelChunkSize
+	}
+	return o.ChunkSize
+}
+
+func (o *DownloadOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return parallelConcurrency
+	}
+	return o.Concurrency
+}
+
+// parallelDownload is the state shared by the workers of DownloadParallel.
+type parallelDownload struct {
+	item     *DriveItem
+	w        io.WriterAt
+	transfer *transfer
+
+	mu          sync.Mutex
+	downloadURL string
+}
+
+func (d *parallelDownload) run(ctx context.Context, chunkSize int64, concurrency int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	size := d.item.DriveItem.Size
+	offsets := make(chan int64)
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsets {
+				if err := d.downloadChunk(ctx, offset, min(chunkSize, size-offset)); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+feed:
+	for offset := int64(0); offset < size; offset += chunkSize {
+		select {
+		case offsets <- offset:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(offsets)
+	wg.Wait()
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
+// downloadChunk downloads length bytes from offset, starting over when the
+// download fails with a transient error.
+func (d *parallelDownload) downloadChunk(ctx context.Context, offset, length int64) error {
+	for attempt := 0; ; attempt++ {
+		downloadURL := d.currentURL()
+		writer := &chunkWriter{
+			ctx:      ctx,
+			w:        d.w,
+			offset:   offset,
+			transfer: d.transfer,
+		}
+		download := d.item.client.DownloadRange
+		if downloadURL != "" {
+			// The download URL is pre-authenticated: no token is sent to the
+			// content host, and an expired URL does not refresh the token.
+			download = d.item.client.DownloadRangeWithoutAuth
+		}
+		err := download(ctx, d.rangeRequest(downloadURL, offset, length), writer)
+		if err == nil && writer.written != length {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil {
+			return nil
+		}
+		d.transfer.add(-writer.written)
+		if isExpiredDownloadURL(err) && downloadURL != "" {
+			if err := d.refreshURL(ctx, downloadURL); err != nil {
+				return err
+			}
+		} else if !isRetryableChunkError(ctx, err) {
+			return err
+		}
+		if attempt >= maxChunkRetries {
+			return err
+		}
+	}
+}
+
+func (d *parallelDownload) currentURL() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.downloadURL
+}
+
+// refreshURL resolves the download URL again unless another worker already
+// replaced stale.
+func (d *parallelDownload) refreshURL(ctx context.Context, stale string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.downloadURL != stale {
+		return nil
+	}
+	fetched, err := newDrive(d.item.core, d.item.targetDrive()).Get(ctx, d.item.targetId())
+	if err != nil {
+		return err
+	}
+	if d.item.DriveItem.RemoteItem == nil && fetched.ETag != d.item.DriveItem.ETag {
+		return ErrItemChanged
+	}
+	d.downloadURL = fetched.DownloadURL
+	return nil
+}
+
+func (d *parallelDownload) rangeRequest(downloadURL string, offset, length int64) http.Request {
+	var u *url.URL
+	if downloadURL != "" {
+		u, _ = url.Parse(downloadURL)
+	} else {
+		u = d.item.url.Download(d.item.targetDrive().Id, d.item.targetId())
+	}
+	req := http.NewJsonRequest(http2.MethodGet, u, nil)
+	return http.WithHeader(req, "Range", byteRange(offset, length))
+}
+
+// isRetryableChunkError reports whether a range failed with an error that may
+// not happen again.
+func isRetryableChunkError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var graphError *GraphError
+	if errors.As(err, &graphError) {
+		return graphError.StatusCode >= http2.StatusInternalServerError || graphError.StatusCode == http2.StatusTooManyRequests
+	}
+	var netError net.Error
+	return errors.As(err, &netError) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// chunkWriter writes a range of a parallel download at its offset.
+type chunkWriter struct {
+	ctx      context.Context
+	w        io.WriterAt
+	offset   int64
+	written  int64
+	transfer *transfer
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		chunk := p[written:min(written+meterChunkSize, len(p))]
+		if err := c.transfer.wait(c.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := c.w.WriteAt(chunk, c.offset+c.written)
+		written += n
+		c.written += int64(n)
+		c.transfer.add(int64(n))
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
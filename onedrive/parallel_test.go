@@ -0,0 +1,162 @@
+package onedrive
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+// writerAtBuffer is an in memory io.WriterAt.
+type writerAtBuffer struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (b *writerAtBuffer) WriteAt(p []byte, off int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if end := int(off) + len(p); end > len(b.data) {
+		b.data = append(b.data, make([]byte, end-len(b.data))...)
+	}
+	return copy(b.data[off:], p), nil
+}
+
+// fakeRangeServer serves content in ranges. The first request for a range
+// starting at an offset in serverErrors fails with a server error, and the
+// first for an offset in shortBodies gets a body cut short.
+type fakeRangeServer struct {
+	content      string
+	serverErrors []int
+	shortBodies  []int
+	status       int
+
+	mu        sync.Mutex
+	requested map[string]int
+	active    int32
+	maxActive int32
+}
+
+func (s *fakeRangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	active := atomic.AddInt32(&s.active, 1)
+	defer atomic.AddInt32(&s.active, -1)
+	s.mu.Lock()
+	s.maxActive = max(s.maxActive, active)
+	byteRange := r.Header.Get("Range")
+	if s.requested == nil {
+		s.requested = map[string]int{}
+	}
+	s.requested[byteRange]++
+	first := s.requested[byteRange] == 1
+	s.mu.Unlock()
+	// Keep the request open so that concurrent requests overlap.
+	time.Sleep(time.Millisecond)
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	for _, offset := range s.serverErrors {
+		if first && strings.HasPrefix(byteRange, "bytes="+strconv.Itoa(offset)+"-") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+	for _, offset := range s.shortBodies {
+		if first && strings.HasPrefix(byteRange, "bytes="+strconv.Itoa(offset)+"-") {
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(s.content[offset : offset+10]))
+			return
+		}
+	}
+	http.ServeContent(w, r, "", time.Time{}, strings.NewReader(s.content))
+}
+
+func setup_parallel_download(t *testing.T, server *fakeRangeServer) (driveItem *DriveItem, teardown func()) {
+	driveItem, mux, teardown := setup_download(t, server.content, "fake_etag")
+	driveItem.client.SetRetryPolicy(nil)
+	mux.Handle("/fake_parallel_url", server)
+	driveItem.DownloadURL = driveItem.url.baseURL.String() + "fake_parallel_url"
+	return driveItem, teardown
+}
+
+func TestDriveItem_DownloadParallel(t *testing.T) {
+	server := &fakeRangeServer{
+		content:      string(fakeContent(1050)),
+		serverErrors: []int{200},
+		shortBodies:  []int{500},
+	}
+	driveItem, teardown := setup_parallel_download(t, server)
+	defer teardown()
+
+	var last Progress
+	options := &DownloadOptions{
+		ChunkSize:   100,
+		Concurrency: 3,
+		Progress:    func(p Progress) { last = p },
+	}
+	writer := &writerAtBuffer{}
+	err := driveItem.DownloadParallel(context.Background(), writer, options)
+	if err != nil {
+		t.Fatalf("DriveItem.DownloadParallel returned error: %v", err)
+	}
+	if string(writer.data) != server.content {
+		t.Errorf("DriveItem.DownloadParallel wrote %d bytes, want the %d bytes of content", len(writer.data), len(server.content))
+	}
+	if len(server.requested) != 11 || server.requested["bytes=200-299"] != 2 || server.requested["bytes=500-599"] != 2 || server.requested["bytes=1000-1049"] != 1 {
+		t.Errorf("DriveItem.DownloadParallel requested ranges %v", server.requested)
+	}
+	if server.maxActive > 3 {
+		t.Errorf("DriveItem.DownloadParallel downloaded %d ranges at once, want at most 3", server.maxActive)
+	}
+	if last.Transferred != 1050 || last.Total != 1050 {
+		t.Errorf("DriveItem.DownloadParallel last reported %+v, want 1050 bytes", last)
+	}
+}
+
+func TestDriveItem_DownloadParallel_Verify(t *testing.T) {
+	server := &fakeRangeServer{content: string(fakeContent(1050))}
+	driveItem, teardown := setup_parallel_download(t, server)
+	defer teardown()
+	driveItem.File = &resources.File{Hashes: resources.Hashes{QuickXorHash: quickXorHashOf([]byte(server.content))}}
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "fake_file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	err = driveItem.DownloadParallel(context.Background(), file, &DownloadOptions{ChunkSize: 100, Verify: true})
+	if err != nil {
+		t.Errorf("DriveItem.DownloadParallel returned error: %v", err)
+	}
+
+	err = driveItem.DownloadParallel(context.Background(), &writerAtBuffer{}, &DownloadOptions{Verify: true})
+	if err != ErrHashUnavailable {
+		t.Errorf("DriveItem.DownloadParallel returned %v, want %v", err, ErrHashUnavailable)
+	}
+}
+
+func TestDriveItem_DownloadParallel_Error(t *testing.T) {
+	server := &fakeRangeServer{content: string(fakeContent(1050)), status: http.StatusNotFound}
+	driveItem, teardown := setup_parallel_download(t, server)
+	defer teardown()
+
+	err := driveItem.DownloadParallel(context.Background(), &writerAtBuffer{}, &DownloadOptions{ChunkSize: 100})
+	if !IsNotFound(err) {
+		t.Errorf("DriveItem.DownloadParallel returned %v, want a not found error", err)
+	}
+	for byteRange, n := range server.requested {
+		if n != 1 {
+			t.Errorf("DriveItem.DownloadParallel requested %v %d times, want once", byteRange, n)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package onedrive
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// meterChunkSize is the largest number of bytes read or written between two
+// progress reports and rate limiter waits.
+const meterChunkSize = 32 * 1024
+
+// Progress describes how far an upload or download has got.
+type Progress struct {
+	// Transferred is the number of bytes of the file sent or received so far.
+	Transferred int64
+	// Total is the size of the file, or -1 while it is unknown.
+	Total int64
+	// Fragment is the index of the upload session fragment being sent,
+	// counting from 0. It is 0 for downloads and single request uploads.
+	Fragment int
+	// ETA estimates the remaining time from the average speed so far. It is
+	// 0 until the speed is known.
+	ETA time.Duration
+}
+
+// ProgressFunc is called as bytes of a transfer are sent or received.
+type ProgressFunc func(Progress)
+
+// ProgressChannel returns a ProgressFunc sending to ch. Reports are dropped
+// while ch is full, so a slow reader only misses intermediate reports.
+func ProgressChannel(ch chan<- Progress) ProgressFunc {
+	return func(p Progress) {
+		select {
+		case ch <- p:
+		default:
+		}
+	}
+}
+
+// transfer meters the bytes of an upload or download: it waits for the rate
+// limiters before moving bytes and reports progress after.
+type transfer struct {
+	progress ProgressFunc
+	limiters []*RateLimiter
+	total    int64
+
+	mu          sync.Mutex
+	start       time.Time
+	offset      int64
+	transferred int64
+}
+
+func newTransfer(total int64, progress ProgressFunc, limiters ...*RateLimiter) *transfer {
+	t := &transfer{
+		progress: progress,
+		total:    total,
+		start:    time.Now(),
+	}
+	for _, limiter := range limiters {
+		if limiter != nil {
+			t.limiters = append(t.limiters, limiter)
+		}
+	}
+	return t
+}
+
+// begin restarts the speed measurement for a transfer starting at offset.
+func (t *transfer) begin(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.start = time.Now()
+	t.offset = offset
+}
+
+func (t *transfer) wait(ctx context.Context, n int) error {
+	for _, limiter := range t.limiters {
+		if err := limiter.WaitN(ctx, int64(n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *transfer) report(transferred int64, fragment int) {
+	if t.progress == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reportLocked(transferred, fragment)
+}
+
+// add reports n more bytes of a transfer whose parts are moved concurrently.
+// n is negative when a part starts over.
+func (t *transfer) add(n int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.transferred += n
+	if t.progress != nil {
+		t.reportLocked(t.transferred, 0)
+	}
+}
+
+// reportLocked calls progress with t.mu held, so that concurrent reports are
+// delivered one at a time.
+func (t *transfer) reportLocked(transferred int64, fragment int) {
+	var eta time.Duration
+	if done := transferred - t.offset; done > 0 && t.total > transferred {
+		elapsed := time.Since(t.start)
+		eta = time.Duration(float64(elapsed) / float64(done) * float64(t.total-transferred))
+	}
+	t.progress(Progress{
+		Transferred: transferred,
+		Total:       t.total,
+		Fragment:    fragment,
+		ETA:         eta,
+	})
+}
+
+// reader meters r, which holds the bytes of the file from offset.
+func (t *transfer) reader(ctx context.Context, r io.Reader, offset int64, fragment int) io.Reader {
+	return &meteredReader{
+		ctx:      ctx,
+		r:        r,
+		transfer: t,
+		offset:   offset,
+		fragment: fragment,
+	}
+}
+
+// writer meters w, which receives the bytes of the file from offset.
+func (t *transfer) writer(ctx context.Context, w io.Writer, offset int64) io.Writer {
+	return &meteredWriter{
+		ctx:      ctx,
+		w:        w,
+		transfer: t,
+		offset:   offset,
+	}
+}
+
+type meteredReader struct {
+	ctx      context.Context
+	r        io.Reader
+	transfer *transfer
+	offset   int64
+	fragment int
+}
+
+func (m *meteredReader) Read(p []byte) (int, error) {
+	if len(p) > meterChunkSize {
+		p = p[:meterChunkSize]
+	}
+	if err := m.transfer.wait(m.ctx, len(p)); err != nil {
+		return 0, err
+	}
+	n, err := m.r.Read(p)
+	if n > 0 {
+		m.offset += int64(n)
+		m.transfer.report(m.offset, m.fragment)
+	}
+	return n, err
+}
+
+type meteredWriter struct {
+	ctx      context.Context
+	w        io.Writer
+	transfer *transfer
+	offset   int64
+}
+
+func (m *meteredWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		chunk := p[written:min(written+meterChunkSize, len(p))]
+		if err := m.transfer.wait(m.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := m.w.Write(chunk)
+		written += n
+		m.offset += int64(n)
+		m.transfer.report(m.offset, 0)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,85 @@
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDriveItem_Upload_Progress(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+	driveItem.client.SetRetryPolicy(nil)
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	size := 7 * fragmentSizeUnit
+	content := fakeContent(2*size + 5)
+	mux.Handle("/fake_upload_url", &fakeUploadEndpoint{t: t, size: len(content), dropped: 2})
+
+	var reports []Progress
+	options := &UploadOptions{
+		FragmentSize: int64(size),
+		Progress:     func(p Progress) { reports = append(reports, p) },
+	}
+	_, err := driveItem.Upload(context.Background(), "fake_file_name", bytes.NewReader(content), int64(len(content)), options)
+	if err != nil {
+		t.Fatalf("DriveItem.Upload returned error: %v", err)
+	}
+	if len(reports) == 0 {
+		t.Fatalf("DriveItem.Upload reported no progress")
+	}
+	last := reports[len(reports)-1]
+	if last.Transferred != int64(len(content)) || last.Total != int64(len(content)) || last.Fragment != 2 || last.ETA != 0 {
+		t.Errorf("DriveItem.Upload last reported %+v, want all %d bytes of fragment 2", last, len(content))
+	}
+	for _, p := range reports {
+		if p.Transferred > p.Total || int64(p.Fragment) != (p.Transferred-1)/int64(size) {
+			t.Errorf("DriveItem.Upload reported %+v", p)
+		}
+	}
+}
+
+func TestDriveItem_Download_Progress(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	content := fakeContent(3*meterChunkSize + 5)
+	driveItem.Size = int64(len(content))
+	driveItem.DownloadURL = driveItem.url.baseURL.String() + "fake_download_url"
+	mux.HandleFunc("/fake_download_url", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	})
+
+	limiter, sleeps := setup_rate_limiter(meterChunkSize, meterChunkSize)
+	client := &Client{core: driveItem.core}
+	client.SetDownloadRateLimiter(limiter)
+	progress := make(chan Progress, 100)
+	writer := &bytes.Buffer{}
+	err := driveItem.Download(context.Background(), writer, &DownloadOptions{Progress: ProgressChannel(progress)})
+	if err != nil {
+		t.Fatalf("DriveItem.Download returned error: %v", err)
+	}
+	if !bytes.Equal(writer.Bytes(), content) {
+		t.Errorf("DriveItem.Download wrote %d bytes, want %d", writer.Len(), len(content))
+	}
+	close(progress)
+	var last Progress
+	for p := range progress {
+		last = p
+	}
+	if last.Transferred != int64(len(content)) || last.Total != int64(len(content)) {
+		t.Errorf("DriveItem.Download last reported %+v, want %d bytes", last, len(content))
+	}
+	var slept time.Duration
+	for _, d := range *sleeps {
+		slept += d
+	}
+	if want := time.Duration(float64(len(content)-meterChunkSize) / meterChunkSize * float64(time.Second)); slept != want {
+		t.Errorf("DriveItem.Download waited %v for the rate limiter, want %v", slept, want)
+	}
+}
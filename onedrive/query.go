@@ -0,0 +1,140 @@
+package onedrive
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Relations of a DriveItem that can be expanded with QueryOptions.Expand.
+const (
+	ExpandChildren    = "children"
+	ExpandPermissions = "permissions"
+	ExpandThumbnails  = "thumbnails"
+)
+
+// OrderField is a property items can be sorted by.
+type OrderField string
+
+const (
+	OrderByName                 OrderField = "name"
+	OrderBySize                 OrderField = "size"
+	OrderByLastModifiedDateTime OrderField = "lastModifiedDateTime"
+)
+
+// SortOrder is the direction of a sort.
+type SortOrder string
+
+const (
+	Ascending  SortOrder = "asc"
+	Descending SortOrder = "desc"
+)
+
+// QueryOptions holds the OData query parameters of a request. Build it with
+// Query and its chained methods:
+//
+//	opts := onedrive.Query().Select("id", "name").OrderBy(onedrive.OrderBySize, onedrive.Descending).Top(10)
+//	children, err := folder.ListChildren(ctx, opts)
+//
+// A nil *QueryOptions adds no parameter.
+type QueryOptions struct {
+	selects   []string
+	expands   []string
+	filter    string
+	orderBy   []string
+	top       int
+	skipToken string
+}
+
+// Query returns empty query options.
+func Query() *QueryOptions {
+	return &QueryOptions{}
+}
+
+// Select limits the properties returned to fields ($select).
+func (q *QueryOptions) Select(fields ...string) *QueryOptions {
+	q.selects = append(q.selects, fields...)
+	return q
+}
+
+// Expand includes related resources such as ExpandThumbnails in the
+// response ($expand).
+func (q *QueryOptions) Expand(relations ...string) *QueryOptions {
+	q.expands = append(q.expands, relations...)
+	return q
+}
+
+// Filter returns only the items matching the OData expression expr, e.g.
+// "file ne null" ($filter).
+func (q *QueryOptions) Filter(expr string) *QueryOptions {
+	q.filter = expr
+	return q
+}
+
+// OrderBy sorts the items by field ($orderby). Further calls sort items with
+// the same value of the previous fields.
+func (q *QueryOptions) OrderBy(field OrderField, order SortOrder) *QueryOptions {
+	q.orderBy = append(q.orderBy, string(field)+" "+string(order))
+	return q
+}
+
+// Top limits the number of items per page ($top).
+func (q *QueryOptions) Top(n int) *QueryOptions {
+	q.top = n
+	return q
+}
+
+// SkipToken continues a listing from the token of a previous page, as
+// returned by Children.SkipToken ($skiptoken).
+func (q *QueryOptions) SkipToken(token string) *QueryOptions {
+	q.skipToken = token
+	return q
+}
+
+// Encode returns the parameters as a query string, in the order $select,
+// $expand, $filter, $orderby, $top and $skiptoken. Lists are separated by
+// commas and values are percent-encoded, spaces as %20.
+func (q *QueryOptions) Encode() string {
+	if q == nil {
+		return ""
+	}
+	var params []string
+	add := func(key, value string) {
+		if value != "" {
+			params = append(params, key+"="+escapeQueryValue(value))
+		}
+	}
+	add("$select", strings.Join(q.selects, ","))
+	add("$expand", strings.Join(q.expands, ","))
+	add("$filter", q.filter)
+	add("$orderby", strings.Join(q.orderBy, ","))
+	if q.top > 0 {
+		add("$top", strconv.Itoa(q.top))
+	}
+	add("$skiptoken", q.skipToken)
+	return strings.Join(params, "&")
+}
+
+// escapeQueryValue escapes value for a query string, keeping the commas
+// separating OData lists readable.
+func escapeQueryValue(value string) string {
+	value = url.QueryEscape(value)
+	value = strings.ReplaceAll(value, "+", "%20")
+	return strings.ReplaceAll(value, "%2C", ",")
+}
+
+// withQuery appends the parameters of the first of opts to the query of u.
+func withQuery(u *url.URL, opts []*QueryOptions) *url.URL {
+	if len(opts) == 0 {
+		return u
+	}
+	encoded := opts[0].Encode()
+	if encoded == "" {
+		return u
+	}
+	if u.RawQuery != "" {
+		encoded = u.RawQuery + "&" + encoded
+	}
+	u.RawQuery = encoded
+	return u
+}
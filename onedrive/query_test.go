@@ -0,0 +1,189 @@
+package onedrive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+func TestQueryOptions_Encode(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *QueryOptions
+		want  string
+	}{
+		{"Nil", nil, ""},
+		{"Empty", Query(), ""},
+		{"Select", Query().Select("id", "name").Select("size"), "$select=id,name,size"},
+		{"Expand", Query().Expand(ExpandThumbnails, ExpandPermissions), "$expand=thumbnails,permissions"},
+		{"ExpandChildren", Query().Expand(ExpandChildren), "$expand=children"},
+		{"Filter", Query().Filter("file ne null"), "$filter=file%20ne%20null"},
+		{"FilterQuotes", Query().Filter("name eq 'a&b=c+d'"), "$filter=name%20eq%20%27a%26b%3Dc%2Bd%27"},
+		{
+			"OrderBy",
+			Query().OrderBy(OrderBySize, Descending).OrderBy(OrderByName, Ascending),
+			"$orderby=size%20desc,name%20asc",
+		},
+		{"OrderByLastModified", Query().OrderBy(OrderByLastModifiedDateTime, Descending), "$orderby=lastModifiedDateTime%20desc"},
+		{"Top", Query().Top(25), "$top=25"},
+		{"TopZero", Query().Top(0), ""},
+		{"SkipToken", Query().SkipToken("a+b/c="), "$skiptoken=a%2Bb%2Fc%3D"},
+		{
+			"All",
+			Query().SkipToken("tok").Top(10).OrderBy(OrderByName, Ascending).Filter("folder ne null").Expand(ExpandThumbnails).Select("id", "name"),
+			"$select=id,name&$expand=thumbnails&$filter=folder%20ne%20null&$orderby=name%20asc&$top=10&$skiptoken=tok",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Encode(); got != tt.want {
+				t.Errorf("QueryOptions.Encode returned %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// setup_query_drive returns a drive whose requests record their query string.
+func setup_query_drive(t *testing.T) (drive *Drive, queries *[]string, teardown func()) {
+	drive, mux, teardown := setup_drive()
+	queries = &[]string{}
+	mux.HandleFunc("/drives/fake_drive_id/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		*queries = append(*queries, r.URL.RawQuery)
+		if strings.HasSuffix(r.URL.Path, "/children") {
+			fmt.Fprint(w, string(readFile(t, "fake_children.json")))
+			return
+		}
+		fmt.Fprint(w, string(readFile(t, "fake_drive_item.json")))
+	})
+	return drive, queries, teardown
+}
+
+func TestQueryOptions_Requests(t *testing.T) {
+	drive, queries, teardown := setup_query_drive(t)
+	defer teardown()
+
+	ctx := context.Background()
+	folder := newDriveItem(drive.core, &resources.DriveItem{Id: "folder_id"}, drive.Drive)
+	query := Query().Select("id", "name").OrderBy(OrderByName, Descending)
+	tests := []struct {
+		name string
+		call func() error
+		want string
+	}{
+		{
+			"Get",
+			func() error { _, err := drive.Get(ctx, "item_id", Query().Expand(ExpandThumbnails)); return err },
+			"$expand=thumbnails",
+		},
+		{
+			"GetByPath",
+			func() error { _, err := drive.GetByPath(ctx, "a b/c", Query().Select("id")); return err },
+			"$select=id",
+		},
+		{
+			"GetByPath_Root",
+			func() error { _, err := drive.GetByPath(ctx, "", Query().Expand(ExpandChildren)); return err },
+			"$expand=children",
+		},
+		{
+			"Get_NoOptions",
+			func() error { _, err := drive.Get(ctx, "item_id"); return err },
+			"",
+		},
+		{
+			"ListChildrenAt",
+			func() error { _, err := drive.ListChildrenAt(ctx, "docs", query); return err },
+			"$select=id,name&$orderby=name%20desc",
+		},
+		{
+			"ListChildren",
+			func() error { _, err := folder.ListChildren(ctx, Query().Filter("file ne null").Top(5)); return err },
+			"$filter=file%20ne%20null&$top=5",
+		},
+		{
+			"ListChildren_NoOptions",
+			func() error { _, err := folder.ListChildren(ctx); return err },
+			"",
+		},
+		{
+			"Children",
+			func() error {
+				_, err := folder.listChildren(ctx, &ChildrenOptions{PageSize: 2, Query: query})
+				return err
+			},
+			"$select=id,name&$orderby=name%20desc&$top=2",
+		},
+		{
+			"Children_QueryTop",
+			func() error {
+				_, err := folder.listChildren(ctx, &ChildrenOptions{PageSize: 2, Query: Query().Top(7)})
+				return err
+			},
+			"$top=7",
+		},
+		{
+			"Children_SkipToken",
+			func() error {
+				_, err := folder.ListChildren(ctx, Query().SkipToken("next_page"))
+				return err
+			},
+			"$skiptoken=next_page",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*queries = nil
+			if err := tt.call(); err != nil {
+				t.Fatalf("%s returned error: %v", tt.name, err)
+			}
+			if len(*queries) != 1 || (*queries)[0] != tt.want {
+				t.Errorf("%s sent queries %q, want [%q]", tt.name, *queries, tt.want)
+			}
+		})
+	}
+	if query.top != 0 {
+		t.Errorf("ChildrenOptions.PageSize changed QueryOptions.Top to %d", query.top)
+	}
+}
+
+func TestChildrenIterator_Query(t *testing.T) {
+	folder, fake, teardown := setup_children_iterator(t, 3)
+	defer teardown()
+
+	options := &ChildrenOptions{Query: Query().Select("id", "name")}
+	items, err := folder.ListAll(context.Background(), 0, options)
+	if err != nil {
+		t.Fatalf("DriveItem.ListAll returned error: %v", err)
+	}
+	if len(items) != 3 {
+		t.Errorf("DriveItem.ListAll returned %d items, want 3", len(items))
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	// The first request fetches the folder, the second the first page.
+	if got, want := fake.queries[1], "$select=id,name"; got != want {
+		t.Errorf("DriveItem.ListAll sent query %q, want %q", got, want)
+	}
+}
+
+func TestChildren_SkipToken(t *testing.T) {
+	tests := []struct {
+		next string
+		want string
+	}{
+		{"", ""},
+		{"https://graph.microsoft.com/v1.0/drives/d/items/i/children?$top=2&$skiptoken=abc%3D%3D", "abc=="},
+		{"https://graph.microsoft.com/v1.0/drives/d/items/i/children?$top=2", ""},
+	}
+	for _, tt := range tests {
+		children := &Children{raw: &resources.Children{NextURL: tt.next}}
+		if got := children.SkipToken(); got != tt.want {
+			t.Errorf("Children.SkipToken returned %q, want %q", got, tt.want)
+		}
+	}
+}
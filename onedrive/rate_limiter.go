@@ -0,0 +1,74 @@
+package onedrive
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bearcatat/onedrive-api/http"
+)
+
+// RateLimiter limits the bandwidth of uploads and downloads with a token
+// bucket. A limiter may be shared by concurrent transfers to cap their
+// combined bandwidth.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int64
+	tokens float64
+	last   time.Time
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRateLimiter returns a limiter allowing bytesPerSecond bytes per second on
+// average and bursts of up to burst bytes. A burst of zero or less allows
+// bursts of one second. A bytesPerSecond of zero or less does not limit the
+// bandwidth.
+func NewRateLimiter(bytesPerSecond, burst int64) *RateLimiter {
+	if burst <= 0 {
+		burst = bytesPerSecond
+	}
+	return &RateLimiter{
+		rate:   float64(bytesPerSecond),
+		burst:  burst,
+		tokens: float64(burst),
+		now:    time.Now,
+		sleep:  http.Sleep,
+	}
+}
+
+// WaitN blocks until n bytes may be transferred or ctx is done. Transfers
+// larger than the burst are let through in bursts. WaitN returns at once
+// when the limiter is nil or has no rate.
+func (l *RateLimiter) WaitN(ctx context.Context, n int64) error {
+	if l == nil || l.rate <= 0 {
+		return nil
+	}
+	for n > 0 {
+		chunk := min(n, l.burst)
+		if err := l.sleep(ctx, l.reserve(chunk)); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// reserve takes n tokens from the bucket and returns how long to wait until
+// they are available.
+func (l *RateLimiter) reserve(n int64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = min(float64(l.burst), l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
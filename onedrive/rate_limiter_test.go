@@ -0,0 +1,74 @@
+package onedrive
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func setup_rate_limiter(bytesPerSecond, burst int64) (limiter *RateLimiter, sleeps *[]time.Duration) {
+	limiter = NewRateLimiter(bytesPerSecond, burst)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sleeps = &[]time.Duration{}
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(ctx context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		now = now.Add(d)
+		return nil
+	}
+	return limiter, sleeps
+}
+
+func TestRateLimiter_WaitN(t *testing.T) {
+	limiter, sleeps := setup_rate_limiter(100, 100)
+
+	if err := limiter.WaitN(context.Background(), 100); err != nil {
+		t.Fatalf("RateLimiter.WaitN returned error: %v", err)
+	}
+	if err := limiter.WaitN(context.Background(), 250); err != nil {
+		t.Fatalf("RateLimiter.WaitN returned error: %v", err)
+	}
+	want := []time.Duration{0, time.Second, time.Second, 500 * time.Millisecond}
+	if !reflect.DeepEqual(*sleeps, want) {
+		t.Errorf("RateLimiter.WaitN slept %v, want %v", *sleeps, want)
+	}
+}
+
+func TestRateLimiter_WaitN_Refill(t *testing.T) {
+	limiter, sleeps := setup_rate_limiter(100, 0)
+
+	limiter.WaitN(context.Background(), 100)
+	limiter.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 10, 0, time.UTC) }
+	limiter.WaitN(context.Background(), 100)
+	want := []time.Duration{0, 0}
+	if !reflect.DeepEqual(*sleeps, want) {
+		t.Errorf("RateLimiter.WaitN slept %v, want %v", *sleeps, want)
+	}
+}
+
+func TestRateLimiter_WaitN_ContextCanceled(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := limiter.WaitN(ctx, 10)
+	if err != context.Canceled {
+		t.Errorf("RateLimiter.WaitN returned %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRateLimiter_WaitN_Unlimited(t *testing.T) {
+	for _, limiter := range []*RateLimiter{nil, NewRateLimiter(0, 0), NewRateLimiter(0, 100), NewRateLimiter(-1, 0)} {
+		done := make(chan error, 1)
+		go func() { done <- limiter.WaitN(context.Background(), 1000) }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("RateLimiter.WaitN returned error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("RateLimiter.WaitN of %+v did not return", limiter)
+		}
+	}
+}
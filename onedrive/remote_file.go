@@ -0,0 +1,310 @@
+package onedrive
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+const (
+	// remoteBlockSize is the default size of the blocks a RemoteFile fetches.
+	remoteBlockSize = 1024 * 1024
+	// remoteCacheBlocks is the default number of blocks a RemoteFile keeps.
+	remoteCacheBlocks = 16
+	// remoteReadAhead is the default number of blocks fetched after a missing one.
+	remoteReadAhead = 1
+)
+
+// OpenOptions configures a RemoteFile. A nil *OpenOptions opens with the
+// defaults.
+type OpenOptions struct {
+	// BlockSize is the size of the ranges fetched from the server. Defaults
+	// to 1 MiB.
+	BlockSize int64
+	// CacheBlocks is the number of blocks kept in memory, the least recently
+	// used one being dropped first. Defaults to 16.
+	CacheBlocks int
+	// ReadAhead is the number of blocks following a missing block fetched in
+	// the same request. Defaults to 1; a negative value fetches only the
+	// missing block.
+	ReadAhead int
+	// RateLimiter limits the bandwidth of the reads.
+	RateLimiter *RateLimiter
+}
+
+// openOptions returns the first of opts with the defaults filled in.
+func openOptions(opts []*OpenOptions) OpenOptions {
+	var options OpenOptions
+	if len(opts) > 0 && opts[0] != nil {
+		options = *opts[0]
+	}
+	if options.BlockSize <= 0 {
+		options.BlockSize = remoteBlockSize
+	}
+	if options.CacheBlocks <= 0 {
+		options.CacheBlocks = remoteCacheBlocks
+	}
+	if options.ReadAhead == 0 {
+		options.ReadAhead = remoteReadAhead
+	}
+	options.ReadAhead = max(options.ReadAhead, 0)
+	return options
+}
+
+// RemoteFile reads the content of a file in blocks fetched with ranged
+// requests. It is safe for concurrent use: blocks are fetched without
+// holding a lock, and readers of a block being fetched wait for it. Read and
+// Seek share an offset, so concurrent calls to Read are serialized.
+type RemoteFile struct {
+	ctx      context.Context
+	item     *DriveItem
+	options  OpenOptions
+	transfer *transfer
+
+	readMu sync.Mutex
+	offset int64
+
+	mu          sync.Mutex
+	downloadURL string
+	blocks      map[int64]*list.Element
+	lru         *list.List
+	fetches     map[int64]*blockFetch
+	closed      bool
+}
+
+// remoteBlock is a cached block of a RemoteFile.
+type remoteBlock struct {
+	index int64
+	data  []byte
+}
+
+// blockFetch is a request for blocks in flight.
+type blockFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// Open returns a RemoteFile reading the content of the item. The item is
+// fetched again for its current size and eTag. Changes to the file are only
+// noticed when an expired download URL is resolved again: reads then fail
+// with ErrItemChanged if the eTag differs. Until then, blocks of the content
+// as of Open are read. ctx applies to all reads.
+func (i *DriveItem) Open(ctx context.Context, opts ...*OpenOptions) (*RemoteFile, error) {
+	item, err := newDrive(i.core, i.targetDrive()).Get(ctx, i.targetId())
+	if err != nil {
+		return nil, err
+	}
+	return newRemoteFile(ctx, item, openOptions(opts)), nil
+}
+
+func newRemoteFile(ctx context.Context, item *DriveItem, options OpenOptions) *RemoteFile {
+	return &RemoteFile{
+		ctx:         ctx,
+		item:        item,
+		options:     options,
+		transfer:    newTransfer(item.DriveItem.Size, nil, options.RateLimiter, item.downloadLimiter),
+		downloadURL: item.DriveItem.DownloadURL,
+		blocks:      map[int64]*list.Element{},
+		lru:         list.New(),
+		fetches:     map[int64]*blockFetch{},
+	}
+}
+
+// Item returns the file as fetched by Open.
+func (f *RemoteFile) Item() *DriveItem {
+	return f.item
+}
+
+// Size returns the size of the file.
+func (f *RemoteFile) Size() int64 {
+	return f.item.DriveItem.Size
+}
+
+// Stat returns the file info of the file, making RemoteFile an fs.File.
+func (f *RemoteFile) Stat() (fs.FileInfo, error) {
+	return fileInfo{f.item}, nil
+}
+
+// Read reads from the current offset.
+func (f *RemoteFile) Read(p []byte) (int, error) {
+	f.readMu.Lock()
+	defer f.readMu.Unlock()
+	n, err := f.readAt(p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt reads len(p) bytes from off. It does not change the offset of Read.
+func (f *RemoteFile) ReadAt(p []byte, off int64) (int, error) {
+	return f.readAt(p, off)
+}
+
+// Seek sets the offset of the next Read.
+func (f *RemoteFile) Seek(offset int64, whence int) (int64, error) {
+	f.readMu.Lock()
+	defer f.readMu.Unlock()
+	if f.isClosed() {
+		return 0, ErrFileClosed
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.Size()
+	default:
+		return 0, ErrInvalidSeek
+	}
+	if offset < 0 {
+		return 0, ErrInvalidSeek
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// Close drops the cached blocks. Reads fail with ErrFileClosed after.
+func (f *RemoteFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	f.blocks = nil
+	f.lru = nil
+	return nil
+}
+
+func (f *RemoteFile) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+func (f *RemoteFile) readAt(p []byte, off int64) (int, error) {
+	if f.isClosed() {
+		return 0, ErrFileClosed
+	}
+	if off < 0 {
+		return 0, ErrInvalidRange
+	}
+	n := 0
+	for n < len(p) && off < f.Size() {
+		index := off / f.options.BlockSize
+		data, err := f.block(index)
+		if err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], data[off-index*f.options.BlockSize:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// block returns the block at index. A missing block is fetched with the
+// blocks read ahead, unless a fetch of the block is in flight: block then
+// waits for it.
+func (f *RemoteFile) block(index int64) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for {
+		if f.closed {
+			return nil, ErrFileClosed
+		}
+		if element, ok := f.blocks[index]; ok {
+			f.lru.MoveToFront(element)
+			return element.Value.(*remoteBlock).data, nil
+		}
+		fetch, ok := f.fetches[index]
+		if !ok {
+			break
+		}
+		f.mu.Unlock()
+		<-fetch.done
+		f.mu.Lock()
+		if fetch.err != nil {
+			return nil, fetch.err
+		}
+	}
+	blockSize := f.options.BlockSize
+	last := min(index+int64(f.options.ReadAhead), (f.Size()-1)/blockSize)
+	for next := index + 1; next <= last; next++ {
+		_, cached := f.blocks[next]
+		_, fetching := f.fetches[next]
+		if cached || fetching {
+			last = next - 1
+			break
+		}
+	}
+	fetch := &blockFetch{done: make(chan struct{})}
+	for i := index; i <= last; i++ {
+		f.fetches[i] = fetch
+	}
+	item := f.downloadItem()
+	f.mu.Unlock()
+	data, err := f.fetch(item, index, last)
+	f.mu.Lock()
+	for i := index; i <= last; i++ {
+		delete(f.fetches, i)
+	}
+	fetch.err = err
+	close(fetch.done)
+	if err != nil {
+		return nil, err
+	}
+	f.downloadURL = item.DriveItem.DownloadURL
+	if f.closed {
+		return nil, ErrFileClosed
+	}
+	for i := last; i >= index; i-- {
+		start := (i - index) * blockSize
+		end := min(start+blockSize, int64(len(data)))
+		f.cache(i, data[start:end:end])
+	}
+	return data[:min(blockSize, int64(len(data)))], nil
+}
+
+// downloadItem returns a copy of the item with the current download URL, so
+// that concurrent fetches can each resolve an expired URL again.
+func (f *RemoteFile) downloadItem() *DriveItem {
+	driveItem := *f.item.DriveItem
+	driveItem.DownloadURL = f.downloadURL
+	item := *f.item
+	item.DriveItem = &driveItem
+	return &item
+}
+
+// fetch downloads the blocks from first to last.
+func (f *RemoteFile) fetch(item *DriveItem, first, last int64) ([]byte, error) {
+	blockSize := f.options.BlockSize
+	offset := first * blockSize
+	length := min((last+1)*blockSize, f.Size()) - offset
+	var buffer bytes.Buffer
+	buffer.Grow(int(length))
+	err := item.download(f.ctx, f.transfer.writer(f.ctx, &buffer, offset), offset, length)
+	if err == nil && int64(buffer.Len()) != length {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// cache adds a block, dropping the least recently used block when the cache
+// is full.
+func (f *RemoteFile) cache(index int64, data []byte) {
+	if f.lru.Len() >= f.options.CacheBlocks {
+		oldest := f.lru.Back()
+		f.lru.Remove(oldest)
+		delete(f.blocks, oldest.Value.(*remoteBlock).index)
+	}
+	f.blocks[index] = f.lru.PushFront(&remoteBlock{index: index, data: data})
+}
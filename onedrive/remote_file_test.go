@@ -0,0 +1,188 @@
+package onedrive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setup_remote_file serves content in ranges with server and the metadata of
+// the item.
+func setup_remote_file(t *testing.T, server *fakeRangeServer) (driveItem *DriveItem, teardown func()) {
+	driveItem, mux, teardown := setup_drive_item()
+	mux.Handle("/fake_download_url", server)
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, `{"id": "fake_drive_item_id", "eTag": "fake_etag", "size": %d, "@microsoft.graph.downloadUrl": %q}`,
+			len(server.content), driveItem.url.baseURL.String()+"fake_download_url")
+	})
+	return driveItem, teardown
+}
+
+func TestDriveItem_Open(t *testing.T) {
+	server := &fakeRangeServer{content: string(fakeContent(1050))}
+	driveItem, teardown := setup_remote_file(t, server)
+	defer teardown()
+
+	file, err := driveItem.Open(context.Background(), &OpenOptions{BlockSize: 100, CacheBlocks: 4, ReadAhead: 2})
+	if err != nil {
+		t.Fatalf("DriveItem.Open returned error: %v", err)
+	}
+	defer file.Close()
+	if file.Size() != 1050 {
+		t.Errorf("RemoteFile.Size returned %d, want %d", file.Size(), 1050)
+	}
+
+	tests := []struct {
+		offset    int64
+		length    int
+		wantErr   error
+		wantRange string
+	}{
+		{0, 10, nil, "bytes=0-299"},
+		{250, 100, nil, "bytes=300-599"},
+		{1040, 20, io.EOF, "bytes=1000-1049"},
+		{450, 10, nil, ""},
+	}
+	for _, tt := range tests {
+		before := len(server.requested)
+		p := make([]byte, tt.length)
+		n, err := file.ReadAt(p, tt.offset)
+		want := server.content[tt.offset:min(tt.offset+int64(tt.length), 1050)]
+		if err != tt.wantErr || string(p[:n]) != want {
+			t.Errorf("RemoteFile.ReadAt(%d) returned %d bytes, %v, want %d bytes, %v", tt.offset, n, err, len(want), tt.wantErr)
+		}
+		if tt.wantRange == "" && len(server.requested) != before {
+			t.Errorf("RemoteFile.ReadAt(%d) requested %v, want cached blocks", tt.offset, server.requested)
+		}
+		if tt.wantRange != "" && server.requested[tt.wantRange] != 1 {
+			t.Errorf("RemoteFile.ReadAt(%d) requested %v, want %v", tt.offset, server.requested, tt.wantRange)
+		}
+	}
+
+	// Blocks 0 to 2 were dropped from the cache when blocks 3 to 5 and 10 were fetched.
+	file.ReadAt(make([]byte, 1), 0)
+	if server.requested["bytes=0-299"] != 2 {
+		t.Errorf("RemoteFile.ReadAt requested %v, want block 0 again", server.requested)
+	}
+}
+
+func TestRemoteFile_Seek(t *testing.T) {
+	server := &fakeRangeServer{content: string(fakeContent(1050))}
+	driveItem, teardown := setup_remote_file(t, server)
+	defer teardown()
+
+	file, err := driveItem.Open(context.Background(), &OpenOptions{BlockSize: 100})
+	if err != nil {
+		t.Fatalf("DriveItem.Open returned error: %v", err)
+	}
+	if _, err := file.Seek(-75, io.SeekEnd); err != nil {
+		t.Errorf("RemoteFile.Seek returned error: %v", err)
+	}
+	got, err := io.ReadAll(file)
+	if err != nil || string(got) != server.content[975:] {
+		t.Errorf("RemoteFile.Read returned %d bytes, %v, want %d bytes", len(got), err, 75)
+	}
+	if _, err := file.Seek(-1, io.SeekStart); err != ErrInvalidSeek {
+		t.Errorf("RemoteFile.Seek returned %v, want %v", err, ErrInvalidSeek)
+	}
+
+	file.Close()
+	if _, err := file.Read(make([]byte, 1)); err != ErrFileClosed {
+		t.Errorf("RemoteFile.Read returned %v, want %v", err, ErrFileClosed)
+	}
+}
+
+func TestRemoteFile_Zip(t *testing.T) {
+	archive := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(archive)
+	for i := range 3 {
+		w, _ := zipWriter.CreateHeader(&zip.FileHeader{Name: fmt.Sprintf("file%d.bin", i), Method: zip.Store})
+		w.Write(fakeContent(5000))
+	}
+	zipWriter.Close()
+	server := &fakeRangeServer{content: archive.String()}
+	driveItem, teardown := setup_remote_file(t, server)
+	defer teardown()
+
+	file, err := driveItem.Open(context.Background(), &OpenOptions{BlockSize: 512, ReadAhead: -1})
+	if err != nil {
+		t.Fatalf("DriveItem.Open returned error: %v", err)
+	}
+	defer file.Close()
+	reader, err := zip.NewReader(file, file.Size())
+	if err != nil {
+		t.Fatalf("zip.NewReader returned error: %v", err)
+	}
+	var names []string
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+	}
+	if fmt.Sprint(names) != "[file0.bin file1.bin file2.bin]" {
+		t.Errorf("zip.Reader listed %v", names)
+	}
+	if len(server.requested) > 3 {
+		t.Errorf("zip.Reader requested %v, want the last blocks of the archive only", server.requested)
+	}
+}
+
+func TestRemoteFile_ReadAt_Concurrent(t *testing.T) {
+	content := string(fakeContent(400))
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+	driveItem.DownloadURL = driveItem.url.baseURL.String() + "fake_download_url"
+	driveItem.Size = int64(len(content))
+
+	// The first request for block 0 is answered once a request for another
+	// block arrived, which cannot happen if fetches hold the lock.
+	var mu sync.Mutex
+	requested := map[string]int{}
+	other := make(chan struct{})
+	mux.HandleFunc("/fake_download_url", func(w http.ResponseWriter, r *http.Request) {
+		byteRange := r.Header.Get("Range")
+		mu.Lock()
+		requested[byteRange]++
+		mu.Unlock()
+		if byteRange == "bytes=0-99" {
+			select {
+			case <-other:
+			case <-time.After(2 * time.Second):
+				t.Errorf("RemoteFile.ReadAt did not fetch blocks concurrently")
+			}
+		} else {
+			close(other)
+		}
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	})
+
+	file := newRemoteFile(context.Background(), driveItem, openOptions([]*OpenOptions{{BlockSize: 100, ReadAhead: -1}}))
+	defer file.Close()
+	var wg sync.WaitGroup
+	read := func(off int64) {
+		defer wg.Done()
+		p := make([]byte, 10)
+		n, err := file.ReadAt(p, off)
+		if err != nil || string(p[:n]) != content[off:off+10] {
+			t.Errorf("RemoteFile.ReadAt(%d) returned %d bytes, %v", off, n, err)
+		}
+	}
+	for range 4 {
+		wg.Add(1)
+		go read(0)
+	}
+	time.Sleep(10 * time.Millisecond)
+	wg.Add(1)
+	go read(200)
+	wg.Wait()
+
+	if requested["bytes=0-99"] != 1 || requested["bytes=200-299"] != 1 {
+		t.Errorf("RemoteFile.ReadAt requested %v, want each block once", requested)
+	}
+}
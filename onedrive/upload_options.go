@@ -0,0 +1,119 @@
+package onedrive
+
+import (
+	"time"
+
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+const (
+	// fragmentSizeUnit is the unit upload session fragments must be a multiple of.
+	fragmentSizeUnit = 320 * 1024
+	// maxFragmentSize is the largest fragment accepted by an upload session.
+	maxFragmentSize = 60 * 1024 * 1024
+)
+
+// UploadOptions configures an upload. A nil *UploadOptions uploads with the
+// defaults.
+type UploadOptions struct {
+	// ConflictBehavior tells the server what to do when a file with the same
+	// name exists. Defaults to resources.ConflictBehaviorRename.
+	ConflictBehavior resources.ConflictBehavior
+	// FragmentSize is the size of the fragments sent to an upload session. It
+	// must be a multiple of 320 KiB and at most 60 MiB. Defaults to 10 MiB.
+	FragmentSize int64
+	// DeferCommit uploads the file through an upload session that creates the
+	// file only when UploadSession.Commit is called. The upload returns
+	// ErrCommitDeferred once all bytes are uploaded; use OnSession to get the
+	// session to commit. Upload sessions cannot hold empty files, so uploading
+	// an empty file with DeferCommit returns ErrEmptyFile.
+	DeferCommit bool
+	// IfMatch fails the upload if the existing file does not have this eTag.
+	IfMatch string
+	// Description sets the description of the file.
+	Description string
+	// CreatedDateTime and LastModifiedDateTime set the times the file was
+	// created and modified on the client. For an empty file they are set, as
+	// is Description, by updating the file once it is uploaded.
+	CreatedDateTime      time.Time
+	LastModifiedDateTime time.Time
+	// OnSession is called with every upload session created for the upload,
+	// e.g. to save it for UploadSession.Resume or to commit it.
+	OnSession func(*UploadSession)
+	// Progress is called as the bytes of the file are sent.
+	Progress ProgressFunc
+	// RateLimiter limits the bandwidth of the upload.
+	RateLimiter *RateLimiter
+	// Verify compares the QuickXorHash of the content sent with the hash of
+	// the uploaded file. On a mismatch the upload returns the file and a
+	// *HashMismatchError.
+	Verify bool
+}
+
+// uploadOptions returns the first of opts, or empty options.
+func uploadOptions(opts []*UploadOptions) (*UploadOptions, error) {
+	if len(opts) == 0 || opts[0] == nil {
+		return &UploadOptions{}, nil
+	}
+	options := opts[0]
+	if size := options.FragmentSize; size < 0 || size%fragmentSizeUnit != 0 || size > maxFragmentSize {
+		return nil, ErrInvalidFragmentSize
+	}
+	return options, nil
+}
+
+func (o *UploadOptions) transfer(c *core, total int64) *transfer {
+	return newTransfer(total, o.Progress, o.RateLimiter, c.uploadLimiter)
+}
+
+func (o *UploadOptions) fragmentSize() int64 {
+	if o.FragmentSize == 0 {
+		return fragmentSize
+	}
+	return o.FragmentSize
+}
+
+// needsSession reports whether the options can only be applied through an
+// upload session.
+func (o *UploadOptions) needsSession() bool {
+	return o.DeferCommit || o.hasMetadata()
+}
+
+func (o *UploadOptions) uploadSessionRequest() *resources.UploadSessionRequest {
+	request := resources.NewUploadSessionRequest(o.ConflictBehavior, o.DeferCommit)
+	request.Item.Description = o.Description
+	request.Item.FileSystemInfo = o.fileSystemInfo()
+	return request
+}
+
+// hasMetadata reports whether the options set properties of the file besides
+// its content.
+func (o *UploadOptions) hasMetadata() bool {
+	return o.Description != "" || o.fileSystemInfo() != nil
+}
+
+// metadata returns the body of a request updating the properties set by the
+// options.
+func (o *UploadOptions) metadata() *resources.DriveItem {
+	return &resources.DriveItem{
+		Description:    o.Description,
+		FileSystemInfo: o.fileSystemInfo(),
+	}
+}
+
+func (o *UploadOptions) fileSystemInfo() *resources.FileSystemInfo {
+	if o.CreatedDateTime.IsZero() && o.LastModifiedDateTime.IsZero() {
+		return nil
+	}
+	return &resources.FileSystemInfo{
+		CreatedDateTime:      o.CreatedDateTime,
+		LastModifiedDateTime: o.LastModifiedDateTime,
+	}
+}
+
+func (o *UploadOptions) conflictBehavior() resources.ConflictBehavior {
+	if o.ConflictBehavior == "" {
+		return resources.ConflictBehaviorRename
+	}
+	return o.ConflictBehavior
+}
@@ -0,0 +1,296 @@
+package onedrive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	http2 "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bearcatat/onedrive-api/http"
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+// UploadSession is an upload session for a large file. Save it with
+// json.Marshal to resume the upload with Client.OpenUploadSession after the
+// process restarts.
+type UploadSession struct {
+	*resources.UploadSession
+	DriveId string `json:"driveId,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Size    int64  `json:"size"`
+	// FragmentSize is the size of the fragments sent, 10 MiB when zero.
+	FragmentSize int64 `json:"fragmentSize,omitempty"`
+	// DeferCommit is set when the file is created only by Commit.
+	DeferCommit bool `json:"deferCommit,omitempty"`
+
+	core *core
+}
+
+func newUploadSession(c *core, session *resources.UploadSession, drive *resources.Drive, name string, size int64) *UploadSession {
+	return &UploadSession{
+		UploadSession: session,
+		DriveId:       drive.Id,
+		Name:          name,
+		Size:          size,
+		core:          c,
+	}
+}
+
+// CreateUploadSession creates an upload session for a file of size bytes named
+// name in the folder. OnSession of the options is not called.
+func (i *DriveItem) CreateUploadSession(ctx context.Context, name string, size int64, opts ...*UploadOptions) (*UploadSession, error) {
+	options, err := uploadOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	session, err := i.createUploadSession(ctx, name, options)
+	if err != nil {
+		return nil, err
+	}
+	uploadSession := newUploadSession(i.core, session, i.targetDrive(), name, size)
+	uploadSession.FragmentSize = options.FragmentSize
+	uploadSession.DeferCommit = options.DeferCommit
+	return uploadSession, nil
+}
+
+// OpenUploadSession reopens an upload session saved with json.Marshal.
+func (c *Client) OpenUploadSession(data []byte) (*UploadSession, error) {
+	var session *UploadSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	if session == nil || session.UploadSession == nil || session.UploadURL == "" {
+		return nil, ErrInvalidUploadSession
+	}
+	session.core = c.core
+	return session, nil
+}
+
+// Status returns the ranges the server still expects.
+func (s *UploadSession) Status(ctx context.Context) (*resources.UploadSessionResponse, error) {
+	var status *resources.UploadSessionResponse
+	err := s.core.client.DoWithoutAuth(ctx, s.statusRequest(), &status)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (s *UploadSession) statusRequest() http.Request {
+	url, _ := url.Parse(s.UploadURL)
+	return http.NewJsonRequest(http2.MethodGet, url, nil)
+}
+
+// Upload uploads the whole file from src, which must be an io.ReaderAt or an
+// io.ReadSeeker. Only Progress and RateLimiter of the options are used.
+func (s *UploadSession) Upload(ctx context.Context, src io.Reader, opts ...*UploadOptions) (*DriveItem, error) {
+	return s.uploadFrom(ctx, src, 0, opts)
+}
+
+// Resume asks the server which bytes it is missing and uploads them from src,
+// which must be an io.ReaderAt or an io.ReadSeeker. Only Progress and
+// RateLimiter of the options are used. When the server has every byte,
+// Resume returns ErrCommitDeferred for a session waiting for Commit and
+// ErrUploadIncomplete otherwise, without sending anything.
+func (s *UploadSession) Resume(ctx context.Context, src io.Reader, opts ...*UploadOptions) (*DriveItem, error) {
+	status, err := s.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	offset, ok := nextExpectedOffset(status.NextExpectedRanges)
+	if !ok && s.DeferCommit {
+		return nil, ErrCommitDeferred
+	}
+	if !ok {
+		return nil, ErrUploadIncomplete
+	}
+	return s.uploadFrom(ctx, src, offset, opts)
+}
+
+func (s *UploadSession) uploadFrom(ctx context.Context, src io.Reader, offset int64, opts []*UploadOptions) (*DriveItem, error) {
+	options, err := uploadOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	readerAt, err := newReaderAt(src)
+	if err != nil {
+		return nil, err
+	}
+	return s.upload(ctx, newReaderAtSource(readerAt, s.Size), offset, options.transfer(s.core, s.Size))
+}
+
+// upload sends fragments from offset until the server returns the item. The
+// next fragment always starts at the first range the server still expects, so
+// dropped or partially accepted fragments are sent again, until the server
+// accepts no byte of maxStalledFragments fragments in a row.
+func (s *UploadSession) upload(ctx context.Context, src fragmentSource, offset int64, transfer *transfer) (*DriveItem, error) {
+	transfer.begin(offset)
+	stalls := 0
+	for {
+		response, err := s.sendFragment(ctx, src, offset, transfer)
+		if err != nil {
+			return nil, err
+		}
+		if response.Id != "" {
+			return newDriveItem(s.core, &response.DriveItem, &resources.Drive{Id: s.DriveId}), nil
+		}
+		next, ok := nextExpectedOffset(response.NextExpectedRanges)
+		if !ok && s.DeferCommit {
+			return nil, ErrCommitDeferred
+		}
+		if !ok {
+			if next, ok = s.reconcile(ctx); !ok {
+				return nil, ErrUploadIncomplete
+			}
+		}
+		if next <= offset {
+			if stalls++; stalls >= maxStalledFragments {
+				return nil, ErrUploadStalled
+			}
+		} else {
+			stalls = 0
+		}
+		offset = next
+	}
+}
+
+// sendFragment sends the fragment at offset. Fragments failing with a
+// transient error are retried from the offset the server reports.
+func (s *UploadSession) sendFragment(ctx context.Context, src fragmentSource, offset int64, transfer *transfer) (*resources.UploadSessionResponse, error) {
+	for retries := 0; ; retries++ {
+		response, err := s.uploadFragment(ctx, src, offset, transfer)
+		if err == nil {
+			return response, nil
+		}
+		if http.IsNotFound(err) {
+			return nil, ErrUploadSessionExpired
+		}
+		if !isRetryableFragmentError(ctx, err) || retries >= maxFragmentRetries {
+			return nil, err
+		}
+		next, ok := s.reconcile(ctx)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if ok {
+			offset = next
+		}
+	}
+}
+
+// reconcile asks the server for the first byte it expects.
+func (s *UploadSession) reconcile(ctx context.Context) (int64, bool) {
+	status, err := s.Status(ctx)
+	if err != nil {
+		return 0, false
+	}
+	return nextExpectedOffset(status.NextExpectedRanges)
+}
+
+func (s *UploadSession) uploadFragment(ctx context.Context, src fragmentSource, offset int64, transfer *transfer) (*resources.UploadSessionResponse, error) {
+	fragment, err := src.fragmentAt(offset, s.fragmentSize())
+	if err != nil {
+		return nil, err
+	}
+	url, _ := url.Parse(s.UploadURL)
+	index := int(offset / s.fragmentSize())
+	req := http.WithBodyReader(http.NewFileFragmentUploadRequest(*url, offset, s.Size, fragment), func(r io.Reader) io.Reader {
+		return transfer.reader(ctx, r, offset, index)
+	})
+	var response *resources.UploadSessionResponse
+	err = s.core.client.DoWithoutAuth(ctx, req, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// isRetryableFragmentError reports whether a fragment failed with an error that
+// may not happen again: a server error, throttling, a range the server did not
+// expect or a connection failure.
+func isRetryableFragmentError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var graphError *GraphError
+	if errors.As(err, &graphError) {
+		return graphError.StatusCode >= http2.StatusInternalServerError ||
+			graphError.StatusCode == http2.StatusTooManyRequests ||
+			graphError.StatusCode == http2.StatusRequestedRangeNotSatisfiable
+	}
+	var urlError *url.Error
+	return errors.As(err, &urlError)
+}
+
+func (s *UploadSession) fragmentSize() int64 {
+	if s.FragmentSize == 0 {
+		return fragmentSize
+	}
+	return s.FragmentSize
+}
+
+// Commit creates the file of a session created with DeferCommit once all its
+// bytes are uploaded.
+func (s *UploadSession) Commit(ctx context.Context) (*DriveItem, error) {
+	var driveItem *resources.DriveItem
+	err := s.core.client.DoWithoutAuth(ctx, s.commitRequest(), &driveItem)
+	if err != nil {
+		return nil, err
+	}
+	return newDriveItem(s.core, driveItem, &resources.Drive{Id: s.DriveId}), nil
+}
+
+func (s *UploadSession) commitRequest() http.Request {
+	url, _ := url.Parse(s.UploadURL)
+	return http.NewContentRequest(http2.MethodPost, url, nil)
+}
+
+// Cancel deletes the upload session and the bytes uploaded so far.
+func (s *UploadSession) Cancel(ctx context.Context) error {
+	return s.core.client.DoWithoutAuth(ctx, s.cancelRequest(), nil)
+}
+
+func (s *UploadSession) cancelRequest() http.Request {
+	url, _ := url.Parse(s.UploadURL)
+	return http.NewJsonRequest(http2.MethodDelete, url, nil)
+}
+
+// nextExpectedOffset returns the start of the first range in ranges such as
+// ["12345-55232", "77829-99375"] or ["26-"].
+func nextExpectedOffset(ranges []string) (int64, bool) {
+	if len(ranges) == 0 {
+		return 0, false
+	}
+	start, _, _ := strings.Cut(ranges[0], "-")
+	offset, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return offset, true
+}
+
+// newReaderAt returns src as an io.ReaderAt.
+func newReaderAt(src io.Reader) (io.ReaderAt, error) {
+	switch s := src.(type) {
+	case io.ReaderAt:
+		return s, nil
+	case io.ReadSeeker:
+		return &readSeekerAt{ReadSeeker: s}, nil
+	}
+	return nil, ErrNotSeekable
+}
+
+type readSeekerAt struct {
+	io.ReadSeeker
+}
+
+func (r *readSeekerAt) ReadAt(p []byte, off int64) (int, error) {
+	if _, err := r.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(r.ReadSeeker, p)
+}
@@ -0,0 +1,516 @@
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+func setup_upload_session(size int64) (session *UploadSession, mux *http.ServeMux, teardown func()) {
+	url, mux, teardown := setup()
+	core := newCore(&http.Client{})
+	core.url.baseURL = url
+	session = newUploadSession(core, &resources.UploadSession{UploadURL: url.String() + "fake_upload_url"}, &resources.Drive{Id: "fake_drive_id"}, "fake_file_name", size)
+	return session, mux, teardown
+}
+
+func TestDriveItem_CreateUploadSession(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		jsonData := readFile(t, "fake_upload_session.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	session, err := driveItem.CreateUploadSession(context.Background(), "fake_file_name", 12)
+	if err != nil {
+		t.Fatalf("DriveItem.CreateUploadSession returned error: %v", err)
+	}
+	expectedUploadSession := getDataFromFile[*resources.UploadSession](t, "fake_upload_session.json")
+	if !reflect.DeepEqual(session.UploadSession, expectedUploadSession) {
+		t.Errorf("DriveItem.CreateUploadSession returned %+v, want %+v", session.UploadSession, expectedUploadSession)
+	}
+	if session.DriveId != "fake_drive_id" || session.Name != "fake_file_name" || session.Size != 12 {
+		t.Errorf("DriveItem.CreateUploadSession returned %+v", session)
+	}
+}
+
+func TestClient_OpenUploadSession(t *testing.T) {
+	session, _, teardown := setup_upload_session(12)
+	defer teardown()
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+	client := NewClient(&http.Client{})
+	reopened, err := client.OpenUploadSession(data)
+	if err != nil {
+		t.Fatalf("Client.OpenUploadSession returned error: %v", err)
+	}
+	if !reflect.DeepEqual(reopened.UploadSession, session.UploadSession) || reopened.DriveId != session.DriveId || reopened.Size != session.Size {
+		t.Errorf("Client.OpenUploadSession returned %+v, want %+v", reopened, session)
+	}
+	if reopened.core != client.core {
+		t.Errorf("Client.OpenUploadSession returned a session not bound to the client")
+	}
+
+	_, err = client.OpenUploadSession([]byte(`{"size": 12}`))
+	if err != ErrInvalidUploadSession {
+		t.Errorf("Client.OpenUploadSession returned %v, want %v", err, ErrInvalidUploadSession)
+	}
+}
+
+func TestUploadSession_Resume(t *testing.T) {
+	content := "hello world!"
+	session, mux, teardown := setup_upload_session(int64(len(content)))
+	defer teardown()
+
+	mux.HandleFunc("/fake_upload_url", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `{"expirationDateTime": "2025-01-31T00:00:00Z", "nextExpectedRanges": ["6-"]}`)
+		case "PUT":
+			testHeader(t, r, "Content-Range", "bytes 6-11/12")
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != "world!" {
+				t.Errorf("Request body: %q, want %q", body, "world!")
+			}
+			w.WriteHeader(http.StatusCreated)
+			jsonData := readFile(t, "fake_drive_item.json")
+			fmt.Fprint(w, string(jsonData))
+		default:
+			t.Errorf("Request method: %v, want GET or PUT", r.Method)
+		}
+	})
+
+	item, err := session.Resume(context.Background(), strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("UploadSession.Resume returned error: %v", err)
+	}
+	expectedItem := getDataFromFile[*resources.DriveItem](t, "fake_drive_item.json")
+	if !reflect.DeepEqual(item.DriveItem, expectedItem) {
+		t.Errorf("UploadSession.Resume returned %+v, want %+v", item.DriveItem, expectedItem)
+	}
+	if item.drive.Id != "fake_drive_id" {
+		t.Errorf("UploadSession.Resume returned item in drive %q, want fake_drive_id", item.drive.Id)
+	}
+}
+
+func TestUploadSession_Resume_NoRangesLeft(t *testing.T) {
+	for _, deferCommit := range []bool{true, false} {
+		session, mux, teardown := setup_upload_session(12)
+		session.DeferCommit = deferCommit
+		mux.HandleFunc("/fake_upload_url", func(w http.ResponseWriter, r *http.Request) {
+			testMethod(t, r, "GET")
+			fmt.Fprint(w, `{"nextExpectedRanges": []}`)
+		})
+
+		want := ErrUploadIncomplete
+		if deferCommit {
+			want = ErrCommitDeferred
+		}
+		_, err := session.Resume(context.Background(), strings.NewReader("hello world!"))
+		if err != want {
+			t.Errorf("UploadSession.Resume with DeferCommit %v returned %v, want %v", deferCommit, err, want)
+		}
+		teardown()
+	}
+}
+
+func TestUploadSession_Upload_ReadSeeker(t *testing.T) {
+	content := []byte("hello world!")
+	session, mux, teardown := setup_upload_session(int64(len(content)))
+	defer teardown()
+
+	mux.HandleFunc("/fake_upload_url", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		testHeader(t, r, "Content-Range", "bytes 0-11/12")
+		w.WriteHeader(http.StatusCreated)
+		jsonData := readFile(t, "fake_drive_item.json")
+		fmt.Fprint(w, string(jsonData))
+	})
+
+	_, err := session.Upload(context.Background(), struct{ io.ReadSeeker }{bytes.NewReader(content)})
+	if err != nil {
+		t.Errorf("UploadSession.Upload returned error: %v", err)
+	}
+}
+
+func TestUploadSession_Upload_NotSeekable(t *testing.T) {
+	session, _, teardown := setup_upload_session(12)
+	defer teardown()
+
+	_, err := session.Upload(context.Background(), &fakeFile{})
+	if err != ErrNotSeekable {
+		t.Errorf("UploadSession.Upload returned %v, want %v", err, ErrNotSeekable)
+	}
+}
+
+func TestUploadSession_Cancel(t *testing.T) {
+	session, mux, teardown := setup_upload_session(12)
+	defer teardown()
+
+	mux.HandleFunc("/fake_upload_url", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	err := session.Cancel(context.Background())
+	if err != nil {
+		t.Errorf("UploadSession.Cancel returned error: %v", err)
+	}
+}
+
+func TestNextExpectedOffset(t *testing.T) {
+	tests := []struct {
+		ranges []string
+		offset int64
+		ok     bool
+	}{
+		{[]string{"26-"}, 26, true},
+		{[]string{"12345-55232", "77829-99375"}, 12345, true},
+		{nil, 0, false},
+		{[]string{"invalid"}, 0, false},
+	}
+	for _, tt := range tests {
+		offset, ok := nextExpectedOffset(tt.ranges)
+		if offset != tt.offset || ok != tt.ok {
+			t.Errorf("nextExpectedOffset(%v) returned %d, %v, want %d, %v", tt.ranges, offset, ok, tt.offset, tt.ok)
+		}
+	}
+}
+
+// fakeUploadEndpoint is an upload URL that keeps the bytes it accepts. The
+// fragments numbered truncated, dropped and expired, counting from 1, are
+// only half accepted, rejected with a server error, or rejected because the
+// session expired. With deferCommit the file is created by a POST. Fragments
+// may give the total length as "*" until the last one. The created file has
+// the hash quickXorHash when it is set.
+type fakeUploadEndpoint struct {
+	t         *testing.T
+	size      int
+	received  []byte
+	totals    []string
+	puts      int
+	committed bool
+
+	truncated, dropped, expired int
+	deferCommit                 bool
+	quickXorHash                string
+}
+
+func (e *fakeUploadEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "PUT" {
+		e.puts++
+	}
+	if e.expired != 0 && e.puts >= e.expired {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": {"code": "itemNotFound", "message": "The upload session was not found"}}`)
+		return
+	}
+	switch r.Method {
+	case "GET":
+		fmt.Fprintf(w, `{"nextExpectedRanges": ["%d-"]}`, len(e.received))
+	case "PUT":
+		body, _ := io.ReadAll(r.Body)
+		var start, end int
+		var total string
+		fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%s", &start, &end, &total)
+		e.totals = append(e.totals, total)
+		if total == "*" && end+1 < e.size {
+			total = strconv.Itoa(e.size)
+		}
+		if start != len(e.received) || total != strconv.Itoa(e.size) || end-start+1 != len(body) {
+			e.t.Errorf("Request Content-Range: %v with %d bytes, want start %d of %d", r.Header.Get("Content-Range"), len(body), len(e.received), e.size)
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		switch e.puts {
+		case e.dropped:
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		case e.truncated:
+			body = body[:len(body)/2]
+		}
+		e.received = append(e.received, body...)
+		if len(e.received) == e.size && e.deferCommit {
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"nextExpectedRanges": []}`)
+			return
+		}
+		if len(e.received) == e.size && e.quickXorHash != "" {
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id": "fake_uploaded_id", "file": {"hashes": {"quickXorHash": %q}}}`, e.quickXorHash)
+			return
+		}
+		if len(e.received) == e.size {
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, string(readFile(e.t, "fake_drive_item.json")))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, `{"nextExpectedRanges": ["%d-"]}`, len(e.received))
+	case "POST":
+		if !e.deferCommit || len(e.received) != e.size {
+			e.t.Errorf("Upload session committed with %d of %d bytes", len(e.received), e.size)
+		}
+		e.committed = true
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, string(readFile(e.t, "fake_drive_item.json")))
+	default:
+		e.t.Errorf("Request method: %v, want GET, PUT or POST", r.Method)
+	}
+}
+
+func fakeContent(size int) []byte {
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	return content
+}
+
+func TestUploadSession_Upload_DroppedFragment(t *testing.T) {
+	content := fakeContent(fragmentSize*2 + 5)
+	session, mux, teardown := setup_upload_session(int64(len(content)))
+	defer teardown()
+	session.core.client.SetRetryPolicy(nil)
+
+	endpoint := &fakeUploadEndpoint{t: t, size: len(content), dropped: 2}
+	mux.Handle("/fake_upload_url", endpoint)
+
+	_, err := session.Upload(context.Background(), bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("UploadSession.Upload returned error: %v", err)
+	}
+	if !bytes.Equal(endpoint.received, content) {
+		t.Errorf("UploadSession.Upload uploaded %d bytes, want the %d bytes of content", len(endpoint.received), len(content))
+	}
+	if endpoint.puts != 4 {
+		t.Errorf("UploadSession.Upload sent %d fragments, want 4", endpoint.puts)
+	}
+}
+
+func TestUploadSession_Upload_Stalled(t *testing.T) {
+	content := fakeContent(fragmentSize*2 + 5)
+	session, mux, teardown := setup_upload_session(int64(len(content)))
+	defer teardown()
+
+	puts := 0
+	mux.HandleFunc("/fake_upload_url", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		io.Copy(io.Discard, r.Body)
+		puts++
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{"nextExpectedRanges": ["0-"]}`)
+	})
+
+	_, err := session.Upload(context.Background(), bytes.NewReader(content))
+	if err != ErrUploadStalled {
+		t.Errorf("UploadSession.Upload returned %v, want %v", err, ErrUploadStalled)
+	}
+	if puts != maxStalledFragments {
+		t.Errorf("UploadSession.Upload sent %d fragments, want %d", puts, maxStalledFragments)
+	}
+}
+
+func TestDriveItem_Upload_TruncatedFragment(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	content := fakeContent(fragmentSize*2 + 5)
+	endpoint := &fakeUploadEndpoint{t: t, size: len(content), truncated: 1}
+	mux.Handle("/fake_upload_url", endpoint)
+
+	// The reader cannot seek, the bytes the server did not accept are sent
+	// again from the last fragment.
+	_, err := driveItem.Upload(context.Background(), "fake_file_name", struct{ io.Reader }{bytes.NewReader(content)}, int64(len(content)))
+	if err != nil {
+		t.Fatalf("DriveItem.Upload returned error: %v", err)
+	}
+	if !bytes.Equal(endpoint.received, content) {
+		t.Errorf("DriveItem.Upload uploaded %d bytes, want the %d bytes of content", len(endpoint.received), len(content))
+	}
+}
+
+func TestDriveItem_Upload_SessionExpired(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	sessions := 0
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		sessions++
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url_%d"}`, driveItem.url.baseURL.String(), sessions)
+	})
+	content := fakeContent(fragmentSize*2 + 5)
+	expired := &fakeUploadEndpoint{t: t, size: len(content), expired: 2}
+	mux.Handle("/fake_upload_url_1", expired)
+	endpoint := &fakeUploadEndpoint{t: t, size: len(content)}
+	mux.Handle("/fake_upload_url_2", endpoint)
+
+	_, err := driveItem.Upload(context.Background(), "fake_file_name", bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("DriveItem.Upload returned error: %v", err)
+	}
+	if sessions != 2 {
+		t.Errorf("DriveItem.Upload created %d upload sessions, want 2", sessions)
+	}
+	if !bytes.Equal(endpoint.received, content) {
+		t.Errorf("DriveItem.Upload uploaded %d bytes, want the %d bytes of content", len(endpoint.received), len(content))
+	}
+}
+
+func TestDriveItem_Upload_SessionExpired_NotSeekable(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	content := fakeContent(fragmentSize + 5)
+	mux.Handle("/fake_upload_url", &fakeUploadEndpoint{t: t, size: len(content), expired: 2})
+
+	_, err := driveItem.Upload(context.Background(), "fake_file_name", struct{ io.Reader }{bytes.NewReader(content)}, int64(len(content)))
+	if err != ErrUploadSessionExpired {
+		t.Errorf("DriveItem.Upload returned %v, want %v", err, ErrUploadSessionExpired)
+	}
+}
+
+func TestDriveItem_Upload_Options(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/content", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		testHeader(t, r, "If-Match", "fake_etag")
+		if got := r.URL.Query().Get("@microsoft.graph.conflictBehavior"); got != "fail" {
+			t.Errorf("Request conflictBehavior: %q, want %q", got, "fail")
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, string(readFile(t, "fake_drive_item.json")))
+	})
+
+	options := &UploadOptions{
+		ConflictBehavior: resources.ConflictBehaviorFail,
+		IfMatch:          "fake_etag",
+	}
+	_, err := driveItem.Upload(context.Background(), "fake_file_name", strings.NewReader("hello"), 5, options)
+	if err != nil {
+		t.Errorf("DriveItem.Upload returned error: %v", err)
+	}
+}
+
+func TestDriveItem_Upload_SessionOptions(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	modified := time.Date(2024, 6, 7, 8, 9, 10, 0, time.UTC)
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		testHeader(t, r, "If-Match", "fake_etag")
+		body := getDataFromRequest[*resources.UploadSessionRequest](t, r)
+		want := &resources.UploadSessionRequest{
+			Item: resources.UploadSessionRequestItem{
+				ConflictBehavior: resources.ConflictBehaviorReplace,
+				Description:      "fake description",
+				FileSystemInfo: &resources.FileSystemInfo{
+					CreatedDateTime:      created,
+					LastModifiedDateTime: modified,
+				},
+			},
+		}
+		if !reflect.DeepEqual(body, want) {
+			t.Errorf("Request body: %+v, want %+v", body, want)
+		}
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	content := fakeContent(2*fragmentSizeUnit + 5)
+	endpoint := &fakeUploadEndpoint{t: t, size: len(content)}
+	mux.Handle("/fake_upload_url", endpoint)
+
+	options := &UploadOptions{
+		ConflictBehavior:     resources.ConflictBehaviorReplace,
+		FragmentSize:         fragmentSizeUnit,
+		IfMatch:              "fake_etag",
+		Description:          "fake description",
+		CreatedDateTime:      created,
+		LastModifiedDateTime: modified,
+	}
+	_, err := driveItem.Upload(context.Background(), "fake_file_name", bytes.NewReader(content), int64(len(content)), options)
+	if err != nil {
+		t.Fatalf("DriveItem.Upload returned error: %v", err)
+	}
+	if endpoint.puts != 3 || !bytes.Equal(endpoint.received, content) {
+		t.Errorf("DriveItem.Upload sent %d bytes in %d fragments, want %d bytes in 3 fragments", len(endpoint.received), endpoint.puts, len(content))
+	}
+}
+
+func TestDriveItem_Upload_DeferCommit(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		body := getDataFromRequest[*resources.UploadSessionRequest](t, r)
+		if !body.DeferCommit {
+			t.Errorf("Request deferCommit: false, want true")
+		}
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	content := []byte("hello world!")
+	endpoint := &fakeUploadEndpoint{t: t, size: len(content), deferCommit: true}
+	mux.Handle("/fake_upload_url", endpoint)
+
+	var session *UploadSession
+	options := &UploadOptions{
+		DeferCommit: true,
+		OnSession:   func(s *UploadSession) { session = s },
+	}
+	_, err := driveItem.Upload(context.Background(), "fake_file_name", bytes.NewReader(content), int64(len(content)), options)
+	if err != ErrCommitDeferred {
+		t.Fatalf("DriveItem.Upload returned %v, want %v", err, ErrCommitDeferred)
+	}
+	if endpoint.committed {
+		t.Fatalf("DriveItem.Upload committed the upload session")
+	}
+
+	item, err := session.Commit(context.Background())
+	if err != nil {
+		t.Fatalf("UploadSession.Commit returned error: %v", err)
+	}
+	expectedItem := getDataFromFile[*resources.DriveItem](t, "fake_drive_item.json")
+	if !reflect.DeepEqual(item.DriveItem, expectedItem) {
+		t.Errorf("UploadSession.Commit returned %+v, want %+v", item.DriveItem, expectedItem)
+	}
+	if !endpoint.committed {
+		t.Errorf("UploadSession.Commit did not commit the upload session")
+	}
+}
+
+func TestDriveItem_Upload_InvalidFragmentSize(t *testing.T) {
+	driveItem, _, teardown := setup_drive_item()
+	defer teardown()
+
+	for _, size := range []int64{-fragmentSizeUnit, 1000, maxFragmentSize + fragmentSizeUnit} {
+		_, err := driveItem.Upload(context.Background(), "fake_file_name", strings.NewReader("hello"), 5, &UploadOptions{FragmentSize: size})
+		if err != ErrInvalidFragmentSize {
+			t.Errorf("DriveItem.Upload with fragment size %d returned %v, want %v", size, err, ErrInvalidFragmentSize)
+		}
+	}
+}
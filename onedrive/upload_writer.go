@@ -0,0 +1,151 @@
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"hash"
+
+	"github.com/bearcatat/onedrive-api/quickxorhash"
+)
+
+// UploadWriter uploads a file of unknown length as it is written. It holds at
+// most one fragment in memory: full fragments are sent through an upload
+// session with an unknown total length, and the last fragment is sent with
+// the length of the file when the writer is closed.
+type UploadWriter struct {
+	ctx      context.Context
+	parent   *DriveItem
+	name     string
+	options  *UploadOptions
+	transfer *transfer
+	hash     hash.Hash
+
+	session *UploadSession
+	buffer  []byte
+	offset  int64
+	item    *DriveItem
+	stalls  int
+	err     error
+	closed  bool
+}
+
+// CreateWriter returns a writer uploading a file named name in the folder.
+// The file is created when the writer is closed. Files smaller than 4 MiB are
+// sent in a single request when the writer is closed. ctx applies to the
+// whole upload.
+func (i *DriveItem) CreateWriter(ctx context.Context, name string, opts ...*UploadOptions) (*UploadWriter, error) {
+	options, err := uploadOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &UploadWriter{
+		ctx:      ctx,
+		parent:   i,
+		name:     name,
+		options:  options,
+		transfer: options.transfer(i.core, -1),
+		hash:     quickxorhash.New(),
+	}, nil
+}
+
+// Write buffers p and sends the buffered bytes once they fill a fragment and
+// more bytes follow.
+func (w *UploadWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	fragmentSize := int(w.options.fragmentSize())
+	if w.buffer == nil {
+		w.buffer = make([]byte, 0, fragmentSize)
+	}
+	written := 0
+	for len(p) > 0 {
+		if len(w.buffer) == fragmentSize {
+			if err := w.flush(); err != nil {
+				w.err = err
+				return written, err
+			}
+		}
+		n := min(fragmentSize-len(w.buffer), len(p))
+		w.buffer = append(w.buffer, p[:n]...)
+		w.hash.Write(p[:n])
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// flush sends the buffer as a fragment of a file of unknown length and keeps
+// the bytes the server did not accept. It fails with ErrUploadStalled once
+// the server accepted no byte of maxStalledFragments fragments in a row.
+func (w *UploadWriter) flush() error {
+	if w.session == nil {
+		session, err := w.parent.CreateUploadSession(w.ctx, w.name, -1, w.options)
+		if err != nil {
+			return err
+		}
+		if w.options.OnSession != nil {
+			w.options.OnSession(session)
+		}
+		w.session = session
+	}
+	src := &bufferSource{data: w.buffer, offset: w.offset}
+	response, err := w.session.sendFragment(w.ctx, src, w.offset, w.transfer)
+	if err != nil {
+		return err
+	}
+	next, ok := nextExpectedOffset(response.NextExpectedRanges)
+	if !ok {
+		return ErrUploadIncomplete
+	}
+	if next < w.offset || next > w.offset+int64(len(w.buffer)) {
+		return ErrCannotRewind
+	}
+	if next == w.offset {
+		if w.stalls++; w.stalls >= maxStalledFragments {
+			return ErrUploadStalled
+		}
+	} else {
+		w.stalls = 0
+	}
+	w.buffer = append(w.buffer[:0], w.buffer[next-w.offset:]...)
+	w.offset = next
+	return nil
+}
+
+// Close sends the last fragment and creates the file.
+func (w *UploadWriter) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	size := w.offset + int64(len(w.buffer))
+	w.transfer.total = size
+	if w.session == nil {
+		w.item, w.err = w.parent.Upload(w.ctx, w.name, bytes.NewReader(w.buffer), size, w.options)
+		return w.err
+	}
+	w.session.Size = size
+	src := &bufferSource{data: w.buffer, offset: w.offset}
+	item, err := w.session.upload(w.ctx, src, w.offset, w.transfer)
+	if err != nil {
+		w.err = err
+		return err
+	}
+	w.item = newDriveItem(w.parent.core, item.DriveItem, w.parent.targetDrive())
+	if w.options.Verify {
+		w.err = w.parent.verifyUpload(w.ctx, w.item, w.hash)
+	}
+	return w.err
+}
+
+// Item returns the uploaded file once Close succeeded.
+func (w *UploadWriter) Item() *DriveItem {
+	return w.item
+}
@@ -0,0 +1,178 @@
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+func writeInChunks(t *testing.T, w *UploadWriter, content []byte, chunkSize int) {
+	for len(content) > 0 {
+		n := min(chunkSize, len(content))
+		written, err := w.Write(content[:n])
+		if err != nil || written != n {
+			t.Fatalf("UploadWriter.Write returned %d, %v, want %d, nil", written, err, n)
+		}
+		content = content[n:]
+	}
+}
+
+func TestDriveItem_CreateWriter(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	size := 7 * fragmentSizeUnit
+	content := fakeContent(2*size + size/2)
+	endpoint := &fakeUploadEndpoint{t: t, size: len(content)}
+	mux.Handle("/fake_upload_url", endpoint)
+
+	writer, err := driveItem.CreateWriter(context.Background(), "fake_file_name", &UploadOptions{FragmentSize: int64(size)})
+	if err != nil {
+		t.Fatalf("DriveItem.CreateWriter returned error: %v", err)
+	}
+	writeInChunks(t, writer, content, 100000)
+	if cap(writer.buffer) != size {
+		t.Errorf("UploadWriter buffered %d bytes, want at most %d", cap(writer.buffer), size)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("UploadWriter.Close returned error: %v", err)
+	}
+
+	if !bytes.Equal(endpoint.received, content) {
+		t.Errorf("UploadWriter uploaded %d bytes, want the %d bytes of content", len(endpoint.received), len(content))
+	}
+	wantTotals := []string{"*", "*", strconv.Itoa(len(content))}
+	if !reflect.DeepEqual(endpoint.totals, wantTotals) {
+		t.Errorf("UploadWriter sent total lengths %v, want %v", endpoint.totals, wantTotals)
+	}
+	expectedItem := getDataFromFile[*resources.DriveItem](t, "fake_drive_item.json")
+	if !reflect.DeepEqual(writer.Item().DriveItem, expectedItem) {
+		t.Errorf("UploadWriter.Item returned %+v, want %+v", writer.Item().DriveItem, expectedItem)
+	}
+	if _, err := writer.Write([]byte("more")); err != ErrWriterClosed {
+		t.Errorf("UploadWriter.Write returned %v, want %v", err, ErrWriterClosed)
+	}
+}
+
+func TestDriveItem_CreateWriter_TruncatedFragment(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	size := 14 * fragmentSizeUnit
+	content := fakeContent(size + 5)
+	endpoint := &fakeUploadEndpoint{t: t, size: len(content), truncated: 1}
+	mux.Handle("/fake_upload_url", endpoint)
+
+	writer, err := driveItem.CreateWriter(context.Background(), "fake_file_name", &UploadOptions{FragmentSize: int64(size)})
+	if err != nil {
+		t.Fatalf("DriveItem.CreateWriter returned error: %v", err)
+	}
+	writeInChunks(t, writer, content, len(content))
+	if err := writer.Close(); err != nil {
+		t.Fatalf("UploadWriter.Close returned error: %v", err)
+	}
+	if !bytes.Equal(endpoint.received, content) {
+		t.Errorf("UploadWriter uploaded %d bytes, want the %d bytes of content", len(endpoint.received), len(content))
+	}
+}
+
+func TestDriveItem_CreateWriter_Stalled(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	puts := 0
+	mux.HandleFunc("/fake_upload_url", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		puts++
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{"nextExpectedRanges": ["0-"]}`)
+	})
+
+	writer, err := driveItem.CreateWriter(context.Background(), "fake_file_name", &UploadOptions{FragmentSize: fragmentSizeUnit})
+	if err != nil {
+		t.Fatalf("DriveItem.CreateWriter returned error: %v", err)
+	}
+	_, err = writer.Write(fakeContent(2 * fragmentSizeUnit))
+	if err != ErrUploadStalled {
+		t.Errorf("UploadWriter.Write returned %v, want %v", err, ErrUploadStalled)
+	}
+	if puts != maxStalledFragments {
+		t.Errorf("UploadWriter sent %d fragments, want %d", puts, maxStalledFragments)
+	}
+}
+
+func TestDriveItem_CreateWriter_Small(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/content", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "hello world!" {
+			t.Errorf("Request body: %q, want %q", body, "hello world!")
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, string(readFile(t, "fake_drive_item.json")))
+	})
+
+	writer, err := driveItem.CreateWriter(context.Background(), "fake_file_name")
+	if err != nil {
+		t.Fatalf("DriveItem.CreateWriter returned error: %v", err)
+	}
+	writeInChunks(t, writer, []byte("hello world!"), 5)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("UploadWriter.Close returned error: %v", err)
+	}
+	if writer.Item() == nil {
+		t.Errorf("UploadWriter.Item returned nil")
+	}
+}
+
+func TestDriveItem_CreateWriter_EmptyWithTimestamps(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/content", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		testHeader(t, r, "Content-Length", "0")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, string(readFile(t, "fake_drive_item.json")))
+	})
+	mux.HandleFunc("/drives/fake_drive_id/items/XXXXXXXXXXXX!105", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		testBody(t, r, &resources.DriveItem{
+			FileSystemInfo: &resources.FileSystemInfo{LastModifiedDateTime: modified},
+		})
+		fmt.Fprint(w, string(readFile(t, "fake_drive_item.json")))
+	})
+
+	writer, err := driveItem.CreateWriter(context.Background(), "fake_file_name", &UploadOptions{LastModifiedDateTime: modified})
+	if err != nil {
+		t.Fatalf("DriveItem.CreateWriter returned error: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("UploadWriter.Close returned error: %v", err)
+	}
+	if writer.Item() == nil {
+		t.Errorf("UploadWriter.Item returned nil")
+	}
+}
@@ -14,8 +14,6 @@ const (
 //  - List shared files
 //  - Recent files
 //  - Search
-//  - Upload
-//  - Query String Parameters
 
 type oneDriveURL struct {
 	baseURL *url.URL
@@ -33,21 +31,66 @@ func (u *oneDriveURL) GetMyDrive() *url.URL {
 	return url
 }
 
-func (u *oneDriveURL) GetDriveItemByPath(driveId, path string) *url.URL {
+// GET /me/drives
+func (u *oneDriveURL) ListMyDrives() *url.URL {
+	return u.baseURL.JoinPath("/me/drives")
+}
+
+// GET /users/{user-id}/drives
+func (u *oneDriveURL) ListUserDrives(userId string) *url.URL {
+	relativePath := fmt.Sprintf("/users/%s/drives", userId)
+	return u.baseURL.JoinPath(relativePath)
+}
+
+// GET /sites/{site-id}/drives
+func (u *oneDriveURL) ListSiteDrives(siteId string) *url.URL {
+	relativePath := fmt.Sprintf("/sites/%s/drives", siteId)
+	return u.baseURL.JoinPath(relativePath)
+}
+
+// GET /groups/{group-id}/drives
+func (u *oneDriveURL) ListGroupDrives(groupId string) *url.URL {
+	relativePath := fmt.Sprintf("/groups/%s/drives", groupId)
+	return u.baseURL.JoinPath(relativePath)
+}
+
+// GET /users/{user-id}/drive
+func (u *oneDriveURL) GetUserDrive(userId string) *url.URL {
+	relativePath := fmt.Sprintf("/users/%s/drive", userId)
+	return u.baseURL.JoinPath(relativePath)
+}
+
+// GET /drives/{drive-id}
+func (u *oneDriveURL) GetDrive(driveId string) *url.URL {
+	relativePath := fmt.Sprintf("/drives/%s", driveId)
+	return u.baseURL.JoinPath(relativePath)
+}
+
+// GET /groups/{group-id}/drive
+func (u *oneDriveURL) GetGroupDrive(groupId string) *url.URL {
+	relativePath := fmt.Sprintf("/groups/%s/drive", groupId)
+	return u.baseURL.JoinPath(relativePath)
+}
+
+// GET /sites/{site-id}/drive
+func (u *oneDriveURL) GetSiteDrive(siteId string) *url.URL {
+	relativePath := fmt.Sprintf("/sites/%s/drive", siteId)
+	return u.baseURL.JoinPath(relativePath)
+}
+
+func (u *oneDriveURL) GetDriveItemByPath(driveId, path string, opts ...*QueryOptions) *url.URL {
 	relativePath := fmt.Sprintf("/drives/%s/root", driveId)
 	if path != "" {
-		path = url.PathEscape(path)
+		path = escapePath(path)
 		relativePath = fmt.Sprintf("/drives/%s/root:/%s", driveId, path)
 	}
-	url := u.baseURL.JoinPath(relativePath)
-	return url
+	return withQuery(u.baseURL.JoinPath(relativePath), opts)
 }
 
 // GET /drives/{drive-id}/items/{item-id}
-func (u *oneDriveURL) Get(driveId, itemId string) *url.URL {
+func (u *oneDriveURL) Get(driveId, itemId string, opts ...*QueryOptions) *url.URL {
 	relativePath := fmt.Sprintf("/drives/%s/items/%s", driveId, itemId)
-	url := u.baseURL.JoinPath(relativePath)
-	return url
+	return withQuery(u.baseURL.JoinPath(relativePath), opts)
 }
 
 func (u *oneDriveURL) CreateFolder(driveId, itemId string) *url.URL {
@@ -57,12 +100,19 @@ func (u *oneDriveURL) CreateFolder(driveId, itemId string) *url.URL {
 }
 
 func (u *oneDriveURL) UploadSession(driveId, itemId, fileName string) *url.URL {
-	fileName = strings.ToValidUTF8(fileName, "x")
+	fileName = url.PathEscape(strings.ToValidUTF8(fileName, "x"))
 	relativePath := fmt.Sprintf("/drives/%s/items/%s:/%s:/createUploadSession", driveId, itemId, fileName)
 	url := u.baseURL.JoinPath(relativePath)
 	return url
 }
 
+// PUT /drives/{drive-id}/items/{parent-id}:/{filename}:/content
+func (u *oneDriveURL) UploadContent(driveId, itemId, fileName string) *url.URL {
+	fileName = url.PathEscape(strings.ToValidUTF8(fileName, "x"))
+	relativePath := fmt.Sprintf("/drives/%s/items/%s:/%s:/content", driveId, itemId, fileName)
+	return u.baseURL.JoinPath(relativePath)
+}
+
 func (u *oneDriveURL) Update(driverId, itemId string) *url.URL {
 	relativePath := fmt.Sprintf("/drives/%s/items/%s", driverId, itemId)
 	return u.baseURL.JoinPath(relativePath)
@@ -87,9 +137,9 @@ func (u *oneDriveURL) Move(driverId, itemId string) *url.URL {
 }
 
 // GET /drives/{drive-id}/items/{item-id}/children
-func (u *oneDriveURL) ListChildren(driverId, itemId string) *url.URL {
+func (u *oneDriveURL) ListChildren(driverId, itemId string, opts ...*QueryOptions) *url.URL {
 	relativePath := fmt.Sprintf("/drives/%s/items/%s/children", driverId, itemId)
-	return u.baseURL.JoinPath(relativePath)
+	return withQuery(u.baseURL.JoinPath(relativePath), opts)
 }
 
 // GET /drives/{drive-id}/items/{item-id}/content
@@ -97,3 +147,47 @@ func (u *oneDriveURL) Download(driverId, itemId string) *url.URL {
 	relativePath := fmt.Sprintf("/drives/%s/items/%s/content", driverId, itemId)
 	return u.baseURL.JoinPath(relativePath)
 }
+
+// GET / PATCH / DELETE /drives/{drive-id}/root:/{item-path}:
+func (u *oneDriveURL) ItemByPath(driveId, path string) *url.URL {
+	return u.baseURL.JoinPath(itemByPath(driveId, path))
+}
+
+// GET / POST /drives/{drive-id}/root:/{item-path}:/children
+func (u *oneDriveURL) ListChildrenByPath(driveId, path string, opts ...*QueryOptions) *url.URL {
+	return withQuery(u.baseURL.JoinPath(itemByPath(driveId, path), "children"), opts)
+}
+
+// GET / PUT /drives/{drive-id}/root:/{item-path}:/content
+func (u *oneDriveURL) ContentByPath(driveId, path string) *url.URL {
+	return u.baseURL.JoinPath(itemByPath(driveId, path), "content")
+}
+
+// POST /drives/{drive-id}/root:/{item-path}:/createUploadSession
+func (u *oneDriveURL) UploadSessionByPath(driveId, path string) *url.URL {
+	return u.baseURL.JoinPath(itemByPath(driveId, path), "createUploadSession")
+}
+
+// POST /drives/{drive-id}/root:/{item-path}:/copy
+func (u *oneDriveURL) CopyByPath(driveId, path string) *url.URL {
+	return u.baseURL.JoinPath(itemByPath(driveId, path), "copy")
+}
+
+// itemByPath addresses the item at path from the root of the drive, or the
+// root itself for an empty path.
+func itemByPath(driveId, path string) string {
+	if strings.Trim(path, "/") == "" {
+		return fmt.Sprintf("/drives/%s/root", driveId)
+	}
+	path = escapePath(strings.ToValidUTF8(path, "x"))
+	return fmt.Sprintf("/drives/%s/root:/%s:", driveId, path)
+}
+
+// escapePath escapes every segment of a slash separated item path.
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,38 @@
+package onedrive
+
+import "testing"
+
+func TestEscapePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"a", "a"},
+		{"/a/b/", "a/b"},
+		{"My Docs/New #1", "My%20Docs/New%20%231"},
+		{"50%/what?", "50%25/what%3F"},
+		{"a+b/c=d", "a+b/c=d"},
+	}
+	for _, tt := range tests {
+		if got := escapePath(tt.path); got != tt.want {
+			t.Errorf("escapePath(%q) returned %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestOneDriveURL_ItemByPath(t *testing.T) {
+	u := newOneDriveURL()
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", "https://graph.microsoft.com/v1.0/drives/d/root"},
+		{"/", "https://graph.microsoft.com/v1.0/drives/d/root"},
+		{"My Docs/a#1.txt", "https://graph.microsoft.com/v1.0/drives/d/root:/My%20Docs/a%231.txt:"},
+	}
+	for _, tt := range tests {
+		if got := u.ItemByPath("d", tt.path).String(); got != tt.want {
+			t.Errorf("oneDriveURL.ItemByPath(%q) returned %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
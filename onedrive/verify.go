@@ -0,0 +1,99 @@
+package onedrive
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"hash"
+
+	"github.com/bearcatat/onedrive-api/quickxorhash"
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+// HashMismatchError is returned when the QuickXorHash of the content sent or
+// received differs from the one OneDrive reports for the file.
+type HashMismatchError struct {
+	// Local is the base64 encoded hash of the content sent or received.
+	Local string
+	// Remote is the quickXorHash of the file in OneDrive.
+	Remote string
+}
+
+func (e *HashMismatchError) Error() string {
+	return fmt.Sprintf("quickXorHash mismatch: local %s, remote %s", e.Local, e.Remote)
+}
+
+// verifyHash compares the hash of the transferred content with the hash of
+// file.
+func verifyHash(local hash.Hash, file *resources.File) error {
+	if file == nil || file.Hashes.QuickXorHash == "" {
+		return ErrHashUnavailable
+	}
+	sum := base64.StdEncoding.EncodeToString(local.Sum(nil))
+	if sum != file.Hashes.QuickXorHash {
+		return &HashMismatchError{
+			Local:  sum,
+			Remote: file.Hashes.QuickXorHash,
+		}
+	}
+	return nil
+}
+
+// verifyUpload compares the hash of the uploaded content with the hash of
+// item, fetching the item again when the upload response has no hash.
+func (c *core) verifyUpload(ctx context.Context, item *DriveItem, local hash.Hash) error {
+	if item.File == nil || item.File.Hashes.QuickXorHash == "" {
+		fetched, err := newDrive(c, item.drive).Get(ctx, item.Id)
+		if err != nil {
+			return err
+		}
+		item.DriveItem = fetched.DriveItem
+	}
+	return verifyHash(local, item.File)
+}
+
+// verifySource verifies an upload of size bytes hashed by src.
+func (c *core) verifySource(ctx context.Context, item *DriveItem, src *hashingSource, size int64) error {
+	if src.hashed != size {
+		return ErrHashUnavailable
+	}
+	return c.verifyUpload(ctx, item, src.hash)
+}
+
+// hashingSource hashes the bytes of the file as its fragments are read for
+// the first time.
+type hashingSource struct {
+	fragmentSource
+	hash   hash.Hash
+	hashed int64
+}
+
+func newHashingSource(src fragmentSource) *hashingSource {
+	return &hashingSource{
+		fragmentSource: src,
+		hash:           quickxorhash.New(),
+	}
+}
+
+func (s *hashingSource) fragmentAt(offset, maxLength int64) ([]byte, error) {
+	fragment, err := s.fragmentSource.fragmentAt(offset, maxLength)
+	if err != nil {
+		return nil, err
+	}
+	// A fragment after bytes that were never read cannot be hashed, the hash
+	// then stays incomplete.
+	if end := offset + int64(len(fragment)); offset <= s.hashed && end > s.hashed {
+		s.hash.Write(fragment[s.hashed-offset:])
+		s.hashed = end
+	}
+	return fragment, nil
+}
+
+func (s *hashingSource) rewind() error {
+	if err := s.fragmentSource.rewind(); err != nil {
+		return err
+	}
+	s.hash.Reset()
+	s.hashed = 0
+	return nil
+}
@@ -0,0 +1,135 @@
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bearcatat/onedrive-api/quickxorhash"
+	"github.com/bearcatat/onedrive-api/resources"
+)
+
+func quickXorHashOf(content []byte) string {
+	sum := quickxorhash.Sum(content)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestDriveItem_Upload_Verify(t *testing.T) {
+	content := "hello world!"
+	tests := []struct {
+		remote string
+		want   error
+	}{
+		{quickXorHashOf([]byte(content)), nil},
+		{quickXorHashOf([]byte("hello")), &HashMismatchError{Local: quickXorHashOf([]byte(content)), Remote: quickXorHashOf([]byte("hello"))}},
+	}
+	for _, tt := range tests {
+		driveItem, mux, teardown := setup_drive_item()
+		mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/content", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id": "fake_uploaded_id", "file": {"hashes": {"quickXorHash": %q}}}`, tt.remote)
+		})
+
+		item, err := driveItem.Upload(context.Background(), "fake_file_name", strings.NewReader(content), int64(len(content)), &UploadOptions{Verify: true})
+		if fmt.Sprint(err) != fmt.Sprint(tt.want) {
+			t.Errorf("DriveItem.Upload returned %v, want %v", err, tt.want)
+		}
+		if item == nil || item.Id != "fake_uploaded_id" {
+			t.Errorf("DriveItem.Upload returned item %+v, want fake_uploaded_id", item)
+		}
+		teardown()
+	}
+}
+
+func TestDriveItem_Upload_Verify_FetchesItem(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	content := []byte("hello world!")
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/content", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "fake_uploaded_id", "file": {}}`)
+	})
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_uploaded_id", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, `{"id": "fake_uploaded_id", "file": {"hashes": {"quickXorHash": %q}}}`, quickXorHashOf(content))
+	})
+
+	item, err := driveItem.Upload(context.Background(), "fake_file_name", bytes.NewReader(content), int64(len(content)), &UploadOptions{Verify: true})
+	if err != nil {
+		t.Fatalf("DriveItem.Upload returned error: %v", err)
+	}
+	if item.File.Hashes.QuickXorHash != quickXorHashOf(content) {
+		t.Errorf("DriveItem.Upload returned hashes %+v, want the fetched hashes", item.File.Hashes)
+	}
+}
+
+func TestDriveItem_UploadLargeFile_Verify(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	content := fakeContent(fragmentSize + 5)
+	mux.Handle("/fake_upload_url", &fakeUploadEndpoint{t: t, size: len(content), truncated: 1, quickXorHash: quickXorHashOf(content)})
+
+	// Bytes sent again after the truncated fragment are hashed once.
+	_, err := driveItem.Upload(context.Background(), "fake_file_name", bytes.NewReader(content), int64(len(content)), &UploadOptions{Verify: true})
+	if err != nil {
+		t.Errorf("DriveItem.Upload returned error: %v", err)
+	}
+}
+
+func TestDriveItem_CreateWriter_Verify(t *testing.T) {
+	driveItem, mux, teardown := setup_drive_item()
+	defer teardown()
+
+	mux.HandleFunc("/drives/fake_drive_id/items/fake_drive_item_id:/fake_file_name:/createUploadSession", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"uploadUrl": "%sfake_upload_url"}`, driveItem.url.baseURL.String())
+	})
+	size := 14 * fragmentSizeUnit
+	content := fakeContent(size + 5)
+	mux.Handle("/fake_upload_url", &fakeUploadEndpoint{t: t, size: len(content), quickXorHash: quickXorHashOf([]byte("other"))})
+
+	writer, err := driveItem.CreateWriter(context.Background(), "fake_file_name", &UploadOptions{FragmentSize: int64(size), Verify: true})
+	if err != nil {
+		t.Fatalf("DriveItem.CreateWriter returned error: %v", err)
+	}
+	writeInChunks(t, writer, content, 100000)
+	var mismatch *HashMismatchError
+	if err := writer.Close(); !errors.As(err, &mismatch) || mismatch.Local != quickXorHashOf(content) {
+		t.Errorf("UploadWriter.Close returned %v, want a *HashMismatchError", err)
+	}
+}
+
+func TestDriveItem_Download_Verify(t *testing.T) {
+	content := []byte("hello world!")
+	tests := []struct {
+		file *resources.File
+		want error
+	}{
+		{&resources.File{Hashes: resources.Hashes{QuickXorHash: quickXorHashOf(content)}}, nil},
+		{&resources.File{Hashes: resources.Hashes{QuickXorHash: quickXorHashOf([]byte("hello"))}}, &HashMismatchError{Local: quickXorHashOf(content), Remote: quickXorHashOf([]byte("hello"))}},
+		{nil, ErrHashUnavailable},
+	}
+	for _, tt := range tests {
+		driveItem, mux, teardown := setup_drive_item()
+		driveItem.File = tt.file
+		driveItem.DownloadURL = driveItem.url.baseURL.String() + "fake_download_url"
+		mux.HandleFunc("/fake_download_url", func(w http.ResponseWriter, r *http.Request) {
+			w.Write(content)
+		})
+
+		err := driveItem.Download(context.Background(), &bytes.Buffer{}, &DownloadOptions{Verify: true})
+		if fmt.Sprint(err) != fmt.Sprint(tt.want) {
+			t.Errorf("DriveItem.Download returned %v, want %v", err, tt.want)
+		}
+		teardown()
+	}
+}
@@ -0,0 +1,80 @@
+// Package quickxorhash implements QuickXorHash, the hash OneDrive computes for
+// the content of files, as described in
+// https://learn.microsoft.com/en-us/onedrive/developer/code-snippets/quickxorhash.
+//
+// OneDrive returns the hash base64 encoded in the quickXorHash property of
+// file.hashes.
+package quickxorhash
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	// Size is the size of a QuickXorHash checksum in bytes.
+	Size = 20
+	// BlockSize is the preferred size of the writes to the hash in bytes.
+	BlockSize = 64
+
+	widthInBits = Size * 8
+	shift       = 11
+)
+
+type digest struct {
+	register [Size]byte
+	// shifted is the bit of the register the next byte is xored into.
+	shifted int
+	length  uint64
+}
+
+// New returns a new hash.Hash computing the QuickXorHash checksum.
+func New() hash.Hash {
+	return &digest{}
+}
+
+// Sum returns the QuickXorHash checksum of data.
+func Sum(data []byte) [Size]byte {
+	var d digest
+	d.Write(data)
+	var sum [Size]byte
+	d.Sum(sum[:0])
+	return sum
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	for _, b := range p {
+		// Xor the byte into the register starting at bit d.shifted, wrapping
+		// around the end of the register.
+		i, offset := d.shifted/8, d.shifted%8
+		v := uint16(b) << offset
+		d.register[i] ^= byte(v)
+		d.register[(i+1)%Size] ^= byte(v >> 8)
+		d.shifted = (d.shifted + shift) % widthInBits
+	}
+	d.length += uint64(len(p))
+	return len(p), nil
+}
+
+// Sum appends the checksum to b. It does not change the state of the hash.
+func (d *digest) Sum(b []byte) []byte {
+	sum := d.register
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], d.length)
+	for i, l := range length {
+		sum[Size-len(length)+i] ^= l
+	}
+	return append(b, sum[:]...)
+}
+
+func (d *digest) Reset() {
+	*d = digest{}
+}
+
+func (d *digest) Size() int {
+	return Size
+}
+
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
@@ -0,0 +1,74 @@
+package quickxorhash
+
+import (
+	"bytes"
+	"encoding/base64"
+	"slices"
+	"testing"
+)
+
+func testData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte((i*7 + 3) % 256)
+	}
+	return data
+}
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{nil, "AAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+		{[]byte{0x4a}, "SgAAAAAAAAAAAAAAAQAAAAAAAAA="},
+		{testData(3), "A1BABAAAAAAAAAAAAwAAAAAAAAA="},
+		{testData(20), "gl0z1HPQERK0gAY7BEISoHAFL5Q="},
+		{testData(159), "7gi7SoTZMRx5gfdOM7shn/kHQ6E="},
+		{testData(160), "7gi7SoTZMRx5gfdODLshn/kHw6o="},
+		{testData(161), "jQi7SoTZMRx5gfdODbshn/kHw6o="},
+		{testData(5000), "4/GMmuF3EXP3HLUJvgacVA3BgPg="},
+		{[]byte("The quick brown fox jumps over the lazy dog"), "bMSlbysmxJL6S75XwfMcQZOpcr4="},
+	}
+	for _, tt := range tests {
+		sum := Sum(tt.data)
+		if got := base64.StdEncoding.EncodeToString(sum[:]); got != tt.want {
+			t.Errorf("Sum of %d bytes returned %v, want %v", len(tt.data), got, tt.want)
+		}
+	}
+}
+
+func TestHash_Write(t *testing.T) {
+	data := testData(5000)
+	want := Sum(data)
+	for _, chunkSize := range []int{1, 7, BlockSize, 160, 1000} {
+		h := New()
+		for chunk := range slices.Chunk(data, chunkSize) {
+			h.Write(chunk)
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("Hash written in chunks of %d bytes returned %x, want %x", chunkSize, got, want)
+		}
+	}
+}
+
+func TestHash_SumKeepsState(t *testing.T) {
+	data := testData(1000)
+	h := New()
+	h.Write(data[:500])
+	h.Sum(nil)
+	h.Write(data[500:])
+	want := Sum(data)
+	if got := h.Sum([]byte("prefix")); !bytes.Equal(got, append([]byte("prefix"), want[:]...)) {
+		t.Errorf("Hash.Sum returned %x, want %x", got, want)
+	}
+
+	h.Reset()
+	empty := Sum(nil)
+	if got := h.Sum(nil); !bytes.Equal(got, empty[:]) {
+		t.Errorf("Hash.Sum after Reset returned %x, want %x", got, empty)
+	}
+	if h.Size() != Size || h.BlockSize() != BlockSize {
+		t.Errorf("Hash.Size, Hash.BlockSize returned %d, %d, want %d, %d", h.Size(), h.BlockSize(), Size, BlockSize)
+	}
+}
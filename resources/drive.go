@@ -1,18 +1,53 @@
 package resources
 
+import "time"
+
+// DriveType is the kind of a drive. Drive.DriveType and
+// ItemReference.DriveType stay plain strings so existing code that sets them
+// keeps compiling; convert with string(DriveTypePersonal) to compare.
+type DriveType string
+
+const (
+	DriveTypePersonal        DriveType = "personal"
+	DriveTypeBusiness        DriveType = "business"
+	DriveTypeDocumentLibrary DriveType = "documentLibrary"
+)
+
 type Drive struct {
-	Id          string `json:"id,omitempty"`
-	Description string `json:"description,omitempty"`
-	DriveType   string `json:"driveType,omitempty"`
-	Name        string `json:"name,omitempty"`
-	Quota       Quota  `json:"quota,omitempty"`
+	Id                   string         `json:"id,omitempty"`
+	CreatedBy            *IdentitySet   `json:"createdBy,omitempty"`
+	CreatedDateTime      time.Time      `json:"createdDateTime,omitzero"`
+	Description          string         `json:"description,omitempty"`
+	DriveType            string         `json:"driveType,omitempty"`
+	LastModifiedBy       *IdentitySet   `json:"lastModifiedBy,omitempty"`
+	LastModifiedDateTime time.Time      `json:"lastModifiedDateTime,omitzero"`
+	Name                 string         `json:"name,omitempty"`
+	Owner                *IdentitySet   `json:"owner,omitempty"`
+	Quota                Quota          `json:"quota,omitzero"`
+	SharepointIds        *SharepointIds `json:"sharepointIds,omitempty"`
+	System               *SystemFacet   `json:"system,omitempty"`
+	WebURL               string         `json:"webUrl,omitempty"`
 }
 
 type Quota struct {
-	Total     int64  `json:"total,omitempty"`
-	Used      int64  `json:"used,omitempty"`
-	Remaining int64  `json:"remaining,omitempty"`
-	Deleted   int64  `json:"deleted,omitempty"`
-	State     string `json:"state,omitempty"`
-	FileCount int64  `json:"fileCount,omitempty"`
+	Total                  int64                   `json:"total,omitempty"`
+	Used                   int64                   `json:"used,omitempty"`
+	Remaining              int64                   `json:"remaining,omitempty"`
+	Deleted                int64                   `json:"deleted,omitempty"`
+	State                  string                  `json:"state,omitempty"`
+	FileCount              int64                   `json:"fileCount,omitempty"`
+	StoragePlanInformation *StoragePlanInformation `json:"storagePlanInformation,omitempty"`
+}
+
+type StoragePlanInformation struct {
+	UpgradeAvailable bool `json:"upgradeAvailable,omitempty"`
+}
+
+// SystemFacet marks drives and items managed by the system.
+type SystemFacet struct {
+}
+
+type Drives struct {
+	Value   []Drive `json:"value,omitempty"`
+	NextURL string  `json:"@odata.nextLink,omitempty"`
 }
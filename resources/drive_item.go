@@ -1,25 +1,114 @@
 package resources
 
+import "time"
+
 type DriveItem struct {
 	DownloadURL string `json:"@microsoft.graph.downloadUrl,omitempty"`
 
-	Audio       *Audio          `json:"audio,omitempty"`
-	CTag        string          `json:"cTag,omitempty"`
-	Deleted     *DeletedFacet   `json:"deleted,omitempty"`
-	Description string          `json:"description,omitempty"`
-	ETag        string          `json:"eTag,omitempty"`
-	File        *File           `json:"file,omitempty"`
-	Folder      *Folder         `json:"folder,omitempty"`
-	Id          string          `json:"id,omitempty"`
-	Image       *Image          `json:"image,omitempty"`
-	Location    *GeoCoordinates `json:"location,omitempty"`
-	Name        string          `json:"name,omitempty"`
-	//Todo RemoteItem
-	Photo           *Photo         `json:"photo,omitempty"`
-	ParentReference *ItemReference `json:"parentReference,omitempty"`
-	Size            int64          `json:"size,omitempty"`
-	Video           *Video         `json:"video,omitempty"`
-	WebURL          string         `json:"webUrl,omitempty"`
+	Audio                *Audio            `json:"audio,omitempty"`
+	Bundle               *Bundle           `json:"bundle,omitempty"`
+	CTag                 string            `json:"cTag,omitempty"`
+	CreatedBy            *IdentitySet      `json:"createdBy,omitempty"`
+	CreatedDateTime      time.Time         `json:"createdDateTime,omitzero"`
+	Deleted              *DeletedFacet     `json:"deleted,omitempty"`
+	Description          string            `json:"description,omitempty"`
+	ETag                 string            `json:"eTag,omitempty"`
+	File                 *File             `json:"file,omitempty"`
+	FileSystemInfo       *FileSystemInfo   `json:"fileSystemInfo,omitempty"`
+	Folder               *Folder           `json:"folder,omitempty"`
+	Id                   string            `json:"id,omitempty"`
+	Image                *Image            `json:"image,omitempty"`
+	LastModifiedBy       *IdentitySet      `json:"lastModifiedBy,omitempty"`
+	LastModifiedDateTime time.Time         `json:"lastModifiedDateTime,omitzero"`
+	Location             *GeoCoordinates   `json:"location,omitempty"`
+	Malware              *Malware          `json:"malware,omitempty"`
+	Name                 string            `json:"name,omitempty"`
+	Package              *Package          `json:"package,omitempty"`
+	Photo                *Photo            `json:"photo,omitempty"`
+	ParentReference      *ItemReference    `json:"parentReference,omitempty"`
+	Publication          *PublicationFacet `json:"publication,omitempty"`
+	RemoteItem           *RemoteItem       `json:"remoteItem,omitempty"`
+	Root                 *Root             `json:"root,omitempty"`
+	SearchResult         *SearchResult     `json:"searchResult,omitempty"`
+	Shared               *Shared           `json:"shared,omitempty"`
+	SharepointIds        *SharepointIds    `json:"sharepointIds,omitempty"`
+	Size                 int64             `json:"size,omitempty"`
+	SpecialFolder        *SpecialFolder    `json:"specialFolder,omitempty"`
+	Video                *Video            `json:"video,omitempty"`
+	WebDavURL            string            `json:"webDavUrl,omitempty"`
+	WebURL               string            `json:"webUrl,omitempty"`
+}
+
+// RemoteItem references an item that lives in another drive, e.g. an item
+// shared with the signed-in user.
+type RemoteItem struct {
+	Id                   string          `json:"id,omitempty"`
+	CreatedBy            *IdentitySet    `json:"createdBy,omitempty"`
+	CreatedDateTime      time.Time       `json:"createdDateTime,omitzero"`
+	File                 *File           `json:"file,omitempty"`
+	FileSystemInfo       *FileSystemInfo `json:"fileSystemInfo,omitempty"`
+	Folder               *Folder         `json:"folder,omitempty"`
+	Image                *Image          `json:"image,omitempty"`
+	LastModifiedBy       *IdentitySet    `json:"lastModifiedBy,omitempty"`
+	LastModifiedDateTime time.Time       `json:"lastModifiedDateTime,omitzero"`
+	Name                 string          `json:"name,omitempty"`
+	Package              *Package        `json:"package,omitempty"`
+	ParentReference      *ItemReference  `json:"parentReference,omitempty"`
+	Shared               *Shared         `json:"shared,omitempty"`
+	SharepointIds        *SharepointIds  `json:"sharepointIds,omitempty"`
+	Size                 int64           `json:"size,omitempty"`
+	SpecialFolder        *SpecialFolder  `json:"specialFolder,omitempty"`
+	Video                *Video          `json:"video,omitempty"`
+	WebDavURL            string          `json:"webDavUrl,omitempty"`
+	WebURL               string          `json:"webUrl,omitempty"`
+}
+
+type FileSystemInfo struct {
+	CreatedDateTime      time.Time `json:"createdDateTime,omitzero"`
+	LastAccessedDateTime time.Time `json:"lastAccessedDateTime,omitzero"`
+	LastModifiedDateTime time.Time `json:"lastModifiedDateTime,omitzero"`
+}
+
+// Root marks the top-level folder of a drive.
+type Root struct {
+}
+
+type Shared struct {
+	Owner          *IdentitySet `json:"owner,omitempty"`
+	Scope          string       `json:"scope,omitempty"`
+	SharedBy       *IdentitySet `json:"sharedBy,omitempty"`
+	SharedDateTime time.Time    `json:"sharedDateTime,omitzero"`
+}
+
+type SpecialFolder struct {
+	Name string `json:"name,omitempty"`
+}
+
+// Package marks a folder that is handled as a single file, e.g. a OneNote notebook.
+type Package struct {
+	Type string `json:"type,omitempty"`
+}
+
+type Bundle struct {
+	ChildCount int    `json:"childCount,omitempty"`
+	Album      *Album `json:"album,omitempty"`
+}
+
+type Album struct {
+	CoverImageItemId string `json:"coverImageItemId,omitempty"`
+}
+
+type Malware struct {
+	Description string `json:"description,omitempty"`
+}
+
+type SearchResult struct {
+	OnClickTelemetryUrl string `json:"onClickTelemetryUrl,omitempty"`
+}
+
+type PublicationFacet struct {
+	Level     string `json:"level,omitempty"`
+	VersionId string `json:"versionId,omitempty"`
 }
 
 type Photo struct {
@@ -66,14 +155,14 @@ type Audio struct {
 }
 
 type File struct {
-	Hashes             Hashes `json:"hashes,omitempty"`
+	Hashes             Hashes `json:"hashes,omitzero"`
 	MIMEType           string `json:"mimeType,omitempty"`
 	ProcessingMetadata bool   `json:"processingMetadata,omitempty"`
 }
 
 type Folder struct {
 	ChildCount int        `json:"childCount,omitempty"`
-	View       FolderView `json:"view,omitempty"`
+	View       FolderView `json:"view,omitzero"`
 }
 
 type Image struct {
@@ -131,12 +220,27 @@ type CreateFolderRequest struct {
 type Facet struct {
 }
 
-func NewUploadSessionRequest() *UploadSessionRequest {
+// ConflictBehavior tells the server what to do when an item with the same
+// name already exists.
+type ConflictBehavior string
+
+const (
+	ConflictBehaviorFail    ConflictBehavior = "fail"
+	ConflictBehaviorReplace ConflictBehavior = "replace"
+	ConflictBehaviorRename  ConflictBehavior = "rename"
+)
+
+// NewUploadSessionRequest returns the body of a createUploadSession request.
+// An empty conflictBehavior renames the new file on conflict.
+func NewUploadSessionRequest(conflictBehavior ConflictBehavior, deferCommit bool) *UploadSessionRequest {
+	if conflictBehavior == "" {
+		conflictBehavior = ConflictBehaviorRename
+	}
 	return &UploadSessionRequest{
 		Item: UploadSessionRequestItem{
-			ConflictBehavior: "rename",
+			ConflictBehavior: conflictBehavior,
 		},
-		DeferCommit: false,
+		DeferCommit: deferCommit,
 	}
 }
 
@@ -147,7 +251,9 @@ type UploadSessionRequest struct {
 
 type UploadSessionRequestItem struct {
 	// FileName         string `json:"name,omitempty"`
-	ConflictBehavior string `json:"@microsoft.graph.conflictBehavior,omitempty"`
+	ConflictBehavior ConflictBehavior `json:"@microsoft.graph.conflictBehavior,omitempty"`
+	Description      string           `json:"description,omitempty"`
+	FileSystemInfo   *FileSystemInfo  `json:"fileSystemInfo,omitempty"`
 }
 
 type UploadSession struct {
@@ -186,6 +292,28 @@ func NewMoveRequest(parentItem *DriveItem, drive *Drive, newName string) *DriveI
 	return r
 }
 
+// NewMoveToPathRequest moves an item to the folder at parentPath, e.g.
+// /drives/{drive-id}/root:/folder.
+func NewMoveToPathRequest(parentPath string, newName string) *DriveItem {
+	return &DriveItem{
+		ParentReference: &ItemReference{
+			Path: parentPath,
+		},
+		Name: newName,
+	}
+}
+
+// NewCopyToPathRequest copies an item to the folder at parentPath of drive.
+func NewCopyToPathRequest(parentPath string, drive *Drive, newName string) *DriveItem {
+	return &DriveItem{
+		ParentReference: &ItemReference{
+			DriveID: drive.Id,
+			Path:    parentPath,
+		},
+		Name: newName,
+	}
+}
+
 type Children struct {
 	Value   []DriveItem `json:"value,omitempty"`
 	NextURL string      `json:"@odata.nextLink,omitempty"`
@@ -0,0 +1,42 @@
+package resources
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDriveItem_RoundTrip(t *testing.T) {
+	item := testRoundTrip[DriveItem](t, "drive_item.json")
+
+	expectedModified := time.Date(2023, 5, 1, 9, 15, 30, 500000000, time.UTC)
+	if item.FileSystemInfo == nil || !item.FileSystemInfo.LastModifiedDateTime.Equal(expectedModified) {
+		t.Errorf("DriveItem.FileSystemInfo = %+v, want lastModifiedDateTime %v", item.FileSystemInfo, expectedModified)
+	}
+	if item.CreatedBy.Application.DisplayName != "OneDrive" {
+		t.Errorf("DriveItem.CreatedBy = %+v, want application OneDrive", item.CreatedBy)
+	}
+	if item.Root == nil || item.Package.Type != "oneNote" || item.SpecialFolder.Name != "photos" {
+		t.Errorf("DriveItem facets = root %+v, package %+v, specialFolder %+v", item.Root, item.Package, item.SpecialFolder)
+	}
+	if item.Bundle.Album.CoverImageItemId != "cover-id" || item.Malware.Description == "" || item.Publication.Level != "published" {
+		t.Errorf("DriveItem facets = bundle %+v, malware %+v, publication %+v", item.Bundle, item.Malware, item.Publication)
+	}
+	if item.RemoteItem == nil || item.RemoteItem.ParentReference.DriveID != "YYYYYYYYYYYY" {
+		t.Errorf("DriveItem.RemoteItem = %+v, want item in drive YYYYYYYYYYYY", item.RemoteItem)
+	}
+	if item.RemoteItem.Shared.Owner.User.DisplayName != "Remote Owner" {
+		t.Errorf("DriveItem.RemoteItem.Shared = %+v, want owner Remote Owner", item.RemoteItem.Shared)
+	}
+}
+
+func TestDriveItem_Marshal_OmitsZeroTimestamps(t *testing.T) {
+	data, err := json.Marshal(NewMoveRequest(&DriveItem{Id: "fake_parent_id"}, &Drive{}, "fake_name"))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "DateTime") {
+		t.Errorf("Marshal returned %s, want no timestamps", data)
+	}
+}
@@ -0,0 +1,56 @@
+package resources
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDrive_RoundTrip(t *testing.T) {
+	drive := testRoundTrip[Drive](t, "drive.json")
+
+	if drive.DriveType != string(DriveTypeDocumentLibrary) {
+		t.Errorf("Drive.DriveType = %q, want %q", drive.DriveType, DriveTypeDocumentLibrary)
+	}
+	expectedCreated := time.Date(2023, 1, 1, 8, 30, 0, 0, time.UTC)
+	if !drive.CreatedDateTime.Equal(expectedCreated) {
+		t.Errorf("Drive.CreatedDateTime = %v, want %v", drive.CreatedDateTime, expectedCreated)
+	}
+	expectedModified := time.Date(2024, 2, 29, 23, 59, 59, 123000000, time.UTC)
+	if !drive.LastModifiedDateTime.Equal(expectedModified) {
+		t.Errorf("Drive.LastModifiedDateTime = %v, want %v", drive.LastModifiedDateTime, expectedModified)
+	}
+	if drive.Owner == nil || drive.Owner.Group == nil || drive.Owner.Group.DisplayName != "Example Team" {
+		t.Errorf("Drive.Owner = %+v, want group Example Team", drive.Owner)
+	}
+	if drive.LastModifiedBy.User.Email != "user@example.com" {
+		t.Errorf("Drive.LastModifiedBy.User.Email = %q, want user@example.com", drive.LastModifiedBy.User.Email)
+	}
+	if drive.SharepointIds == nil || drive.SharepointIds.SiteUrl != "https://example.sharepoint.com/sites/team" {
+		t.Errorf("Drive.SharepointIds = %+v", drive.SharepointIds)
+	}
+	if drive.System == nil {
+		t.Errorf("Drive.System = nil, want the system facet")
+	}
+	if drive.Quota.StoragePlanInformation == nil || !drive.Quota.StoragePlanInformation.UpgradeAvailable {
+		t.Errorf("Drive.Quota.StoragePlanInformation = %+v", drive.Quota.StoragePlanInformation)
+	}
+}
+
+func TestDrive_Decode_Personal(t *testing.T) {
+	var drive *Drive
+	if err := json.Unmarshal(readFile(t, "drive_personal.json"), &drive); err != nil {
+		t.Fatalf("Unmarshal drive_personal.json failed: %v", err)
+	}
+
+	if drive.DriveType != string(DriveTypePersonal) {
+		t.Errorf("Drive.DriveType = %q, want %q", drive.DriveType, DriveTypePersonal)
+	}
+	if !drive.CreatedDateTime.IsZero() {
+		t.Errorf("Drive.CreatedDateTime = %v, want zero", drive.CreatedDateTime)
+	}
+	expectedQuota := Quota{Total: 5368709120, Remaining: 5368709120, State: "normal"}
+	if drive.Quota != expectedQuota {
+		t.Errorf("Drive.Quota = %+v, want %+v", drive.Quota, expectedQuota)
+	}
+}
@@ -0,0 +1,26 @@
+package resources
+
+// IdentitySet is a keyed collection of the identities involved in an action,
+// e.g. the user and the application that created an item.
+type IdentitySet struct {
+	Application *Identity `json:"application,omitempty"`
+	Device      *Identity `json:"device,omitempty"`
+	Group       *Identity `json:"group,omitempty"`
+	User        *Identity `json:"user,omitempty"`
+}
+
+type Identity struct {
+	DisplayName string `json:"displayName,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Id          string `json:"id,omitempty"`
+}
+
+type SharepointIds struct {
+	ListId           string `json:"listId,omitempty"`
+	ListItemId       string `json:"listItemId,omitempty"`
+	ListItemUniqueId string `json:"listItemUniqueId,omitempty"`
+	SiteId           string `json:"siteId,omitempty"`
+	SiteUrl          string `json:"siteUrl,omitempty"`
+	TenantId         string `json:"tenantId,omitempty"`
+	WebId            string `json:"webId,omitempty"`
+}
@@ -0,0 +1,39 @@
+package resources
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func readFile(t *testing.T, fileName string) []byte {
+	t.Helper()
+	testData, err := os.ReadFile("testdata/" + fileName)
+	if err != nil {
+		t.Fatalf("readTestData failed: %v", err)
+	}
+	return testData
+}
+
+// testRoundTrip decodes the fixture into T, encodes it again and checks that
+// no field was lost or changed on the way.
+func testRoundTrip[T any](t *testing.T, fileName string) *T {
+	t.Helper()
+	data := readFile(t, fileName)
+	var resource *T
+	if err := json.Unmarshal(data, &resource); err != nil {
+		t.Fatalf("Unmarshal %s failed: %v", fileName, err)
+	}
+	encoded, err := json.Marshal(resource)
+	if err != nil {
+		t.Fatalf("Marshal %s failed: %v", fileName, err)
+	}
+	var expected, got map[string]interface{}
+	json.Unmarshal(data, &expected)
+	json.Unmarshal(encoded, &got)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Round trip of %s returned %s, want %s", fileName, encoded, data)
+	}
+	return resource
+}
@@ -0,0 +1,14 @@
+package vfs_test
+
+import (
+	"testing"
+
+	"github.com/bearcatat/onedrive-api/vfs"
+	"github.com/bearcatat/onedrive-api/vfs/vfstest"
+)
+
+func TestMemFS(t *testing.T) {
+	vfstest.TestFileSystem(t, func(t *testing.T) vfs.FileSystem {
+		return vfs.NewMemFS()
+	})
+}
@@ -0,0 +1,57 @@
+// Package vfs defines a writable file system interface, so code written
+// against it can run on the local disk, in memory or on OneDrive by swapping
+// the implementation.
+//
+// Names are slash separated paths as accepted by fs.ValidPath, e.g.
+// "docs/report.txt"; "." names the root.
+package vfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"time"
+)
+
+var (
+	ErrNotDir   = errors.New("not a directory")
+	ErrIsDir    = errors.New("is a directory")
+	ErrNotEmpty = errors.New("directory not empty")
+)
+
+// FileSystem is a writable file system. Errors are *fs.PathError or
+// *fs.LinkError values wrapping fs.ErrNotExist, fs.ErrExist, fs.ErrInvalid,
+// ErrNotDir, ErrIsDir, ErrNotEmpty or errors.ErrUnsupported where those apply.
+type FileSystem interface {
+	fs.StatFS
+	fs.ReadDirFS
+
+	// Create creates or truncates the file name for writing.
+	Create(name string) (File, error)
+	// OpenFile opens name with flags of os.OpenFile, e.g. os.O_RDONLY or
+	// os.O_WRONLY|os.O_CREATE|os.O_TRUNC. Implementations may return
+	// errors.ErrUnsupported for combinations they cannot provide.
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+	// Mkdir creates the folder name. Its parent must exist.
+	Mkdir(name string, perm fs.FileMode) error
+	// MkdirAll creates the folder name and any missing parents. It returns
+	// nil if name already is a folder.
+	MkdirAll(name string, perm fs.FileMode) error
+	// Remove removes the file or empty folder name.
+	Remove(name string) error
+	// RemoveAll removes name and everything it contains. It returns nil if
+	// name does not exist.
+	RemoveAll(name string) error
+	// Rename moves oldname to newname, replacing newname if it is a file.
+	Rename(oldname, newname string) error
+	// Chtimes changes the access and modification times of name. A zero
+	// time leaves the time unchanged.
+	Chtimes(name string, atime, mtime time.Time) error
+}
+
+// File is a file or folder opened on a FileSystem. Files opened for reading
+// implement io.Seeker and io.ReaderAt, and folders fs.ReadDirFile.
+type File interface {
+	fs.File
+	io.Writer
+}
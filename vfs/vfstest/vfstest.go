@@ -0,0 +1,320 @@
+// Package vfstest implements a conformance suite for vfs.FileSystem
+// implementations.
+package vfstest
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/bearcatat/onedrive-api/vfs"
+)
+
+// TestFileSystem runs the conformance suite against file systems returned by
+// newFS, which must return an empty file system on every call. Files are only
+// written through Create and os.O_WRONLY, and read back once closed.
+func TestFileSystem(t *testing.T, newFS func(t *testing.T) vfs.FileSystem) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, fsys vfs.FileSystem)
+	}{
+		{"Create", testCreate},
+		{"OpenFile", testOpenFile},
+		{"Mkdir", testMkdir},
+		{"MkdirAll", testMkdirAll},
+		{"ReadDir", testReadDir},
+		{"Remove", testRemove},
+		{"RemoveAll", testRemoveAll},
+		{"Rename", testRename},
+		{"Chtimes", testChtimes},
+		{"DotNames", testDotNames},
+		{"InvalidPath", testInvalidPath},
+		{"FS", testFS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newFS(t))
+		})
+	}
+}
+
+func testCreate(t *testing.T, fsys vfs.FileSystem) {
+	writeFile(t, fsys, "a.txt", "hello")
+	checkFile(t, fsys, "a.txt", "hello")
+	info, err := fsys.Stat("a.txt")
+	if err != nil || info.Name() != "a.txt" || info.Size() != 5 || info.IsDir() {
+		t.Errorf("Stat returned %v, %v, want a 5 byte file named a.txt", info, err)
+	}
+
+	writeFile(t, fsys, "a.txt", "hi")
+	checkFile(t, fsys, "a.txt", "hi")
+	writeFile(t, fsys, "empty.txt", "")
+	checkFile(t, fsys, "empty.txt", "")
+
+	_, err = fsys.Create("missing/a.txt")
+	checkError(t, "Create in a missing folder", err, fs.ErrNotExist)
+}
+
+func testOpenFile(t *testing.T, fsys vfs.FileSystem) {
+	file, err := fsys.OpenFile("new.txt", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		t.Fatalf("OpenFile with O_EXCL returned error: %v", err)
+	}
+	file.Write([]byte("new"))
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	checkFile(t, fsys, "new.txt", "new")
+
+	_, err = fsys.OpenFile("new.txt", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	checkError(t, "OpenFile of an existing file with O_EXCL", err, fs.ErrExist)
+	_, err = fsys.OpenFile("missing.txt", os.O_WRONLY|os.O_TRUNC, 0666)
+	checkError(t, "OpenFile of a missing file without O_CREATE", err, fs.ErrNotExist)
+	_, err = fsys.Open("missing.txt")
+	checkError(t, "Open of a missing file", err, fs.ErrNotExist)
+
+	file, err = fsys.OpenFile("new.txt", os.O_RDONLY, 0)
+	if err != nil {
+		t.Fatalf("OpenFile with O_RDONLY returned error: %v", err)
+	}
+	defer file.Close()
+	p := make([]byte, 10)
+	if n, _ := file.Read(p); string(p[:n]) != "new" {
+		t.Errorf("Read returned %q, want %q", p[:n], "new")
+	}
+}
+
+func testMkdir(t *testing.T, fsys vfs.FileSystem) {
+	if err := fsys.Mkdir("dir", 0777); err != nil {
+		t.Fatalf("Mkdir returned error: %v", err)
+	}
+	checkDir(t, fsys, "dir")
+	checkError(t, "Mkdir of an existing folder", fsys.Mkdir("dir", 0777), fs.ErrExist)
+	checkError(t, "Mkdir in a missing folder", fsys.Mkdir("missing/dir", 0777), fs.ErrNotExist)
+
+	writeFile(t, fsys, "dir/a.txt", "a")
+	checkFile(t, fsys, "dir/a.txt", "a")
+}
+
+func testMkdirAll(t *testing.T, fsys vfs.FileSystem) {
+	if err := fsys.MkdirAll("a/b/c", 0777); err != nil {
+		t.Fatalf("MkdirAll returned error: %v", err)
+	}
+	checkDir(t, fsys, "a")
+	checkDir(t, fsys, "a/b")
+	checkDir(t, fsys, "a/b/c")
+	if err := fsys.MkdirAll("a/b/c", 0777); err != nil {
+		t.Errorf("MkdirAll of an existing folder returned error: %v", err)
+	}
+	if err := fsys.MkdirAll(".", 0777); err != nil {
+		t.Errorf("MkdirAll of the root returned error: %v", err)
+	}
+
+	writeFile(t, fsys, "a/file", "")
+	checkError(t, "MkdirAll below a file", fsys.MkdirAll("a/file/d", 0777), vfs.ErrNotDir)
+}
+
+func testReadDir(t *testing.T, fsys vfs.FileSystem) {
+	writeFile(t, fsys, "b.txt", "b")
+	writeFile(t, fsys, "a.txt", "a")
+	if err := fsys.Mkdir("c", 0777); err != nil {
+		t.Fatalf("Mkdir returned error: %v", err)
+	}
+	entries, err := fsys.ReadDir(".")
+	if err != nil {
+		t.Fatalf("ReadDir returned error: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if !slices.Equal(names, []string{"a.txt", "b.txt", "c"}) || entries[0].IsDir() || !entries[2].IsDir() {
+		t.Errorf("ReadDir returned %v, want a.txt, b.txt and the folder c", entries)
+	}
+
+	entries, err = fsys.ReadDir("c")
+	if err != nil || len(entries) != 0 {
+		t.Errorf("ReadDir of an empty folder returned %v, %v", entries, err)
+	}
+	_, err = fsys.ReadDir("a.txt")
+	checkError(t, "ReadDir of a file", err, vfs.ErrNotDir)
+	_, err = fsys.ReadDir("missing")
+	checkError(t, "ReadDir of a missing folder", err, fs.ErrNotExist)
+}
+
+func testRemove(t *testing.T, fsys vfs.FileSystem) {
+	writeFile(t, fsys, "a.txt", "a")
+	if err := fsys.Remove("a.txt"); err != nil {
+		t.Errorf("Remove returned error: %v", err)
+	}
+	checkMissing(t, fsys, "a.txt")
+
+	if err := fsys.MkdirAll("dir/sub", 0777); err != nil {
+		t.Fatalf("MkdirAll returned error: %v", err)
+	}
+	checkError(t, "Remove of a folder that is not empty", fsys.Remove("dir"), vfs.ErrNotEmpty)
+	checkDir(t, fsys, "dir/sub")
+	if err := fsys.Remove("dir/sub"); err != nil {
+		t.Errorf("Remove of an empty folder returned error: %v", err)
+	}
+	checkMissing(t, fsys, "dir/sub")
+	checkError(t, "Remove of a missing file", fsys.Remove("missing"), fs.ErrNotExist)
+}
+
+func testRemoveAll(t *testing.T, fsys vfs.FileSystem) {
+	if err := fsys.MkdirAll("dir/sub", 0777); err != nil {
+		t.Fatalf("MkdirAll returned error: %v", err)
+	}
+	writeFile(t, fsys, "dir/sub/a.txt", "a")
+	writeFile(t, fsys, "dir2.txt", "b")
+	if err := fsys.RemoveAll("dir"); err != nil {
+		t.Errorf("RemoveAll returned error: %v", err)
+	}
+	checkMissing(t, fsys, "dir")
+	checkMissing(t, fsys, "dir/sub/a.txt")
+	checkFile(t, fsys, "dir2.txt", "b")
+	if err := fsys.RemoveAll("missing"); err != nil {
+		t.Errorf("RemoveAll of a missing file returned error: %v", err)
+	}
+}
+
+func testRename(t *testing.T, fsys vfs.FileSystem) {
+	if err := fsys.MkdirAll("dir/sub", 0777); err != nil {
+		t.Fatalf("MkdirAll returned error: %v", err)
+	}
+	writeFile(t, fsys, "a.txt", "a")
+	if err := fsys.Rename("a.txt", "dir/b.txt"); err != nil {
+		t.Errorf("Rename returned error: %v", err)
+	}
+	checkMissing(t, fsys, "a.txt")
+	checkFile(t, fsys, "dir/b.txt", "a")
+
+	writeFile(t, fsys, "c.txt", "c")
+	if err := fsys.Rename("c.txt", "dir/b.txt"); err != nil {
+		t.Errorf("Rename over a file returned error: %v", err)
+	}
+	checkMissing(t, fsys, "c.txt")
+	checkFile(t, fsys, "dir/b.txt", "c")
+
+	if err := fsys.Rename("dir", "moved"); err != nil {
+		t.Errorf("Rename of a folder returned error: %v", err)
+	}
+	checkMissing(t, fsys, "dir")
+	checkDir(t, fsys, "moved/sub")
+	checkFile(t, fsys, "moved/b.txt", "c")
+
+	checkError(t, "Rename of a missing file", fsys.Rename("missing", "other"), fs.ErrNotExist)
+	checkError(t, "Rename into a missing folder", fsys.Rename("moved/b.txt", "missing/b.txt"), fs.ErrNotExist)
+}
+
+func testChtimes(t *testing.T, fsys vfs.FileSystem) {
+	writeFile(t, fsys, "a.txt", "a")
+	mtime := time.Date(2020, 5, 6, 7, 8, 9, 0, time.UTC)
+	if err := fsys.Chtimes("a.txt", time.Time{}, mtime); err != nil {
+		t.Fatalf("Chtimes returned error: %v", err)
+	}
+	info, err := fsys.Stat("a.txt")
+	if err != nil || !info.ModTime().Equal(mtime) {
+		t.Errorf("Stat after Chtimes returned %v, %v, want modification time %v", info, err, mtime)
+	}
+	checkError(t, "Chtimes of a missing file", fsys.Chtimes("missing", mtime, mtime), fs.ErrNotExist)
+}
+
+// testDotNames checks that names starting with a dot are not confused with
+// the same names without it.
+func testDotNames(t *testing.T, fsys vfs.FileSystem) {
+	writeFile(t, fsys, "env", "plain")
+	writeFile(t, fsys, ".env", "dot")
+	checkFile(t, fsys, ".env", "dot")
+	checkFile(t, fsys, "env", "plain")
+
+	if err := fsys.MkdirAll(".cache/x", 0777); err != nil {
+		t.Fatalf("MkdirAll returned error: %v", err)
+	}
+	checkDir(t, fsys, ".cache/x")
+	checkMissing(t, fsys, "cache")
+
+	if err := fsys.Rename(".env", ".cache/x/.env"); err != nil {
+		t.Errorf("Rename returned error: %v", err)
+	}
+	checkMissing(t, fsys, ".env")
+	checkFile(t, fsys, ".cache/x/.env", "dot")
+	checkFile(t, fsys, "env", "plain")
+
+	if err := fsys.Remove(".cache/x/.env"); err != nil {
+		t.Errorf("Remove returned error: %v", err)
+	}
+	checkMissing(t, fsys, ".cache/x/.env")
+	if err := fsys.RemoveAll(".cache"); err != nil {
+		t.Errorf("RemoveAll returned error: %v", err)
+	}
+	checkMissing(t, fsys, ".cache")
+	checkFile(t, fsys, "env", "plain")
+}
+
+func testInvalidPath(t *testing.T, fsys vfs.FileSystem) {
+	_, err := fsys.Create("/a.txt")
+	checkError(t, "Create of an absolute path", err, fs.ErrInvalid)
+	checkError(t, "Mkdir of a path with ..", fsys.Mkdir("../dir", 0777), fs.ErrInvalid)
+	checkError(t, "Remove of the root", fsys.Remove("."), fs.ErrInvalid)
+	checkError(t, "Rename of a folder into itself", fsys.Rename("dir", "dir/sub"), fs.ErrInvalid)
+}
+
+func testFS(t *testing.T, fsys vfs.FileSystem) {
+	if err := fsys.MkdirAll("dir/sub", 0777); err != nil {
+		t.Fatalf("MkdirAll returned error: %v", err)
+	}
+	writeFile(t, fsys, "a.txt", "hello world!")
+	writeFile(t, fsys, "dir/b.txt", "")
+	writeFile(t, fsys, "dir/sub/c.txt", "c")
+	if err := fstest.TestFS(fsys, "a.txt", "dir/b.txt", "dir/sub/c.txt"); err != nil {
+		t.Error(err)
+	}
+}
+
+func writeFile(t *testing.T, fsys vfs.FileSystem, name, content string) {
+	t.Helper()
+	file, err := fsys.Create(name)
+	if err != nil {
+		t.Fatalf("Create(%q) returned error: %v", name, err)
+	}
+	if _, err := file.Write([]byte(content)); err != nil {
+		t.Fatalf("Write to %q returned error: %v", name, err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close of %q returned error: %v", name, err)
+	}
+}
+
+func checkFile(t *testing.T, fsys vfs.FileSystem, name, want string) {
+	t.Helper()
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil || string(content) != want {
+		t.Errorf("ReadFile(%q) returned %q, %v, want %q", name, content, err, want)
+	}
+}
+
+func checkDir(t *testing.T, fsys vfs.FileSystem, name string) {
+	t.Helper()
+	info, err := fsys.Stat(name)
+	if err != nil || !info.IsDir() {
+		t.Errorf("Stat(%q) returned %v, %v, want a folder", name, info, err)
+	}
+}
+
+func checkMissing(t *testing.T, fsys vfs.FileSystem, name string) {
+	t.Helper()
+	_, err := fsys.Stat(name)
+	checkError(t, "Stat of "+name, err, fs.ErrNotExist)
+}
+
+func checkError(t *testing.T, op string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("%s returned %v, want %v", op, err, want)
+	}
+}